@@ -1,8 +1,17 @@
 module github.com/terraform-providers/terraform-provider-http
 
 require (
+	cloud.google.com/go v0.61.0
+	github.com/aws/aws-sdk-go v1.25.3
 	github.com/golang/groupcache v0.0.0-20200121045136-8c9f03a8e57e // indirect
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.0.3
+	github.com/jcmturner/gokrb5/v8 v8.4.1
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
+	golang.org/x/oauth2 v0.0.0-20200107190931-bf48bf16ab8d
+	golang.org/x/text v0.3.3
+	golang.org/x/time v0.0.0-20191024005414-555d28b269f0
+	google.golang.org/api v0.29.0
 	google.golang.org/appengine v1.6.6 // indirect
 )
 
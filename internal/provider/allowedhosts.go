@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// hostAllowlist holds the host name globs of the provider's allowed_hosts. A
+// glob such as *.example.com matches subdomains at any depth, as * matches
+// dots, but not example.com itself.
+type hostAllowlist struct {
+	patterns []string
+}
+
+func newHostAllowlist(v []interface{}) *hostAllowlist {
+	a := &hostAllowlist{}
+	for _, pattern := range v {
+		a.patterns = append(a.patterns, strings.ToLower(pattern.(string)))
+	}
+
+	return a
+}
+
+// allows reports whether host, a host name without port, matches one of the
+// patterns.
+func (a *hostAllowlist) allows(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range a.patterns {
+		// The patterns are checked when the provider is configured.
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostNotAllowedError is returned for a request not sent because its host is
+// not in allowed_hosts.
+type hostNotAllowedError struct {
+	host string
+}
+
+func (e *hostNotAllowedError) Error() string {
+	return fmt.Sprintf("requests to %s are not allowed by the provider's allowed_hosts", e.host)
+}
+
+// allowedHostsTransport fails requests to hosts not in the allowlist. As the
+// client sends redirects through it too, redirects to those hosts fail as
+// well.
+type allowedHostsTransport struct {
+	base      http.RoundTripper
+	allowlist *hostAllowlist
+}
+
+func (t *allowedHostsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host := req.URL.Hostname(); !t.allowlist.allows(host) {
+		// A RoundTripper must close the request body, even on errors.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, &hostNotAllowedError{host: host}
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHostAllowlist(t *testing.T) {
+	allowlist := newHostAllowlist([]interface{}{"api.example.com", "*.internal.example.com", "10.0.0.?"})
+
+	cases := map[string]bool{
+		"api.example.com":             true,
+		"API.Example.com":             true,
+		"a.internal.example.com":      true,
+		"a.b.internal.example.com":    true,
+		"10.0.0.1":                    true,
+		"internal.example.com":        false,
+		"example.com":                 false,
+		"api.example.com.attacker.io": false,
+		"10.0.0.10":                   false,
+		"":                            false,
+	}
+
+	for host, expected := range cases {
+		t.Run(host, func(t *testing.T) {
+			if allowed := allowlist.allows(host); allowed != expected {
+				t.Fatalf("expected allows(%q) to be %t, got %t", host, expected, allowed)
+			}
+		})
+	}
+}
+
+func TestAllowedHostsTransport(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://disallowed.example.com/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &allowedHostsTransport{
+			base:      http.DefaultTransport,
+			allowlist: newHostAllowlist([]interface{}{"127.0.0.1"}),
+		},
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	// The redirect is not followed.
+	_, err = client.Get(server.URL + "/redirect")
+	var hostErr *hostNotAllowedError
+	if !errors.As(err, &hostErr) {
+		t.Fatalf("expected the redirect to be blocked, got %v", err)
+	}
+	if hostErr.host != "disallowed.example.com" {
+		t.Fatalf("expected host disallowed.example.com, got %s", hostErr.host)
+	}
+
+	_, err = client.Get(strings.Replace(server.URL, "127.0.0.1", "localhost", 1))
+	if !errors.As(err, &hostErr) {
+		t.Fatalf("expected the request to be blocked, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Fatalf("expected 2 requests to reach the server, got %d", requests)
+	}
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// applyBasicAuth sets the request's Authorization header from the basic_auth
+// block, if configured.
+func applyBasicAuth(req *http.Request, d *schema.ResourceData) error {
+	v := d.Get("basic_auth").([]interface{})
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("basic_auth conflicts with the Authorization request header")
+	}
+
+	m := v[0].(map[string]interface{})
+	req.SetBasicAuth(m["username"].(string), m["password"].(string))
+
+	return nil
+}
+
+// applyBearerToken sets the request's Authorization header from the
+// bearer_token argument, if configured.
+func applyBearerToken(req *http.Request, d *schema.ResourceData) error {
+	token := d.Get("bearer_token").(string)
+	if token == "" {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("bearer_token conflicts with the Authorization request header")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// applyAWSSigV4 signs the request with AWS Signature Version 4 from the
+// aws_sigv4 block, if configured. It must be called once all other headers
+// have been set, as the signature covers them along with the body.
+func applyAWSSigV4(req *http.Request, d *schema.ResourceData, body []byte) error {
+	v := d.Get("aws_sigv4").([]interface{})
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("aws_sigv4 conflicts with the Authorization request header")
+	}
+
+	m := v[0].(map[string]interface{})
+
+	var creds *credentials.Credentials
+	if accessKey := m["access_key"].(string); accessKey != "" {
+		creds = credentials.NewStaticCredentials(accessKey, m["secret_key"].(string), m["session_token"].(string))
+	} else {
+		sess, err := session.NewSessionWithOptions(session.Options{
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return fmt.Errorf("Error loading AWS credentials: %s", err)
+		}
+		creds = sess.Config.Credentials
+	}
+
+	signer := v4.NewSigner(creds)
+	if _, err := signer.Sign(req, bytes.NewReader(body), m["service"].(string), m["region"].(string), time.Now()); err != nil {
+		return fmt.Errorf("Error signing request with AWS SigV4: %s", err)
+	}
+
+	return nil
+}
+
+// withAuthorization returns a copy of req with the given Authorization header
+// and a fresh copy of the body, for resending a request in answer to an
+// authentication challenge.
+func withAuthorization(req *http.Request, authorization string) (*http.Request, error) {
+	r := req.Clone(req.Context())
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body cannot be resent for authentication")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	r.Header.Set("Authorization", authorization)
+
+	return r, nil
+}
+
+// discardBody reads a little of the body of a response that is not returned
+// before closing it, so that the connection can be reused for the request
+// answering the challenge.
+func discardBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+}
+
+// hasRequestHeader reports whether the data source's request_headers sets the
+// named header. Header names are compared case-insensitively. Headers from the
+// provider's default_headers are not considered, as authentication arguments
+// take precedence over them.
+func hasRequestHeader(d *schema.ResourceData, name string) bool {
+	for k := range d.Get("request_headers").(map[string]interface{}) {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// matchBodyRegex matches pattern against body. match is the first capture
+// group of the leftmost match, or the whole match when the pattern has no
+// groups, and captures holds every group. A body that does not match yields
+// empty values, or an error when strict is set.
+func matchBodyRegex(body []byte, pattern string, strict bool) (match string, captures []string, err error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", nil, err
+	}
+
+	submatches := re.FindSubmatch(body)
+	if submatches == nil {
+		if strict {
+			return "", nil, fmt.Errorf("pattern %q does not match the response body", pattern)
+		}
+		return "", []string{}, nil
+	}
+
+	// Groups that did not take part in the match are empty.
+	captures = make([]string, 0, len(submatches)-1)
+	for _, s := range submatches[1:] {
+		captures = append(captures, string(s))
+	}
+
+	if len(captures) == 0 {
+		return string(submatches[0]), captures, nil
+	}
+
+	return captures[0], captures, nil
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchBodyRegex(t *testing.T) {
+	cases := map[string]struct {
+		Body           string
+		Pattern        string
+		Strict         bool
+		ExpectMatch    string
+		ExpectCaptures []string
+		ExpectErr      bool
+	}{
+		"capture group": {
+			Body:           "version=1.2.3",
+			Pattern:        `version=(\S+)`,
+			ExpectMatch:    "1.2.3",
+			ExpectCaptures: []string{"1.2.3"},
+		},
+		"several groups": {
+			Body:           "version=1.2.3",
+			Pattern:        `(\d+)\.(\d+)\.(\d+)`,
+			ExpectMatch:    "1",
+			ExpectCaptures: []string{"1", "2", "3"},
+		},
+		"no groups": {
+			Body:           "version=1.2.3",
+			Pattern:        `\d+\.\d+`,
+			ExpectMatch:    "1.2",
+			ExpectCaptures: []string{},
+		},
+		"unmatched optional group": {
+			Body:           "version=1.2",
+			Pattern:        `(\d+)\.(\d+)(?:\.(\d+))?`,
+			ExpectMatch:    "1",
+			ExpectCaptures: []string{"1", "2", ""},
+		},
+		"multi-line body": {
+			Body:           "name=test\nversion=1.2.3\n",
+			Pattern:        `(?m)^version=(.*)$`,
+			ExpectMatch:    "1.2.3",
+			ExpectCaptures: []string{"1.2.3"},
+		},
+		"no match": {
+			Body:           "version=1.2.3",
+			Pattern:        `release=(\S+)`,
+			ExpectCaptures: []string{},
+		},
+		"no match strict": {
+			Body:      "version=1.2.3",
+			Pattern:   `release=(\S+)`,
+			Strict:    true,
+			ExpectErr: true,
+		},
+		"invalid pattern": {
+			Body:      "version=1.2.3",
+			Pattern:   `version=(`,
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			match, captures, err := matchBodyRegex([]byte(tc.Body), tc.Pattern, tc.Strict)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if match != tc.ExpectMatch {
+				t.Errorf("expected match %q, got %q", tc.ExpectMatch, match)
+			}
+			if !reflect.DeepEqual(captures, tc.ExpectCaptures) {
+				t.Errorf("expected captures %q, got %q", tc.ExpectCaptures, captures)
+			}
+		})
+	}
+}
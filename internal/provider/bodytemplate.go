@@ -0,0 +1,29 @@
+package provider
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// parseBodyTemplate parses the text of body_template. A reference to a
+// variable missing from body_vars is an error rather than rendering as
+// "<no value>".
+func parseBodyTemplate(text string) (*template.Template, error) {
+	return template.New("body_template").Option("missingkey=error").Parse(text)
+}
+
+// renderBodyTemplate renders the body_template text with vars, the values of
+// body_vars.
+func renderBodyTemplate(text string, vars map[string]interface{}) ([]byte, error) {
+	tmpl, err := parseBodyTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, vars); err != nil {
+		return nil, err
+	}
+
+	return body.Bytes(), nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestRenderBodyTemplate(t *testing.T) {
+	cases := map[string]struct {
+		Template   string
+		Vars       map[string]interface{}
+		ExpectBody string
+		ExpectErr  string
+	}{
+		"variable": {
+			Template:   `{"name": "{{.name}}"}`,
+			Vars:       map[string]interface{}{"name": "x"},
+			ExpectBody: `{"name": "x"}`,
+		},
+		"functions": {
+			Template:   `{"name": {{printf "%q" .name}}}`,
+			Vars:       map[string]interface{}{"name": `x "y"`},
+			ExpectBody: `{"name": "x \"y\""}`,
+		},
+		"no variables": {
+			Template:   "static",
+			ExpectBody: "static",
+		},
+		"missing variable": {
+			Template:  "{{.nmae}}",
+			Vars:      map[string]interface{}{"name": "x"},
+			ExpectErr: `template: body_template:1:2: executing "body_template" at <.nmae>: map has no entry for key "nmae"`,
+		},
+		"syntax error": {
+			Template:  "{{.name",
+			ExpectErr: `template: body_template:1: unclosed action`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			body, err := renderBodyTemplate(tc.Template, tc.Vars)
+			if tc.ExpectErr != "" {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				if err.Error() != tc.ExpectErr {
+					t.Fatalf("expected error %q, got %q", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(body) != tc.ExpectBody {
+				t.Fatalf("expected body %q, got %q", tc.ExpectBody, body)
+			}
+		})
+	}
+}
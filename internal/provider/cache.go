@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// responseCache holds responses shared by the data sources of one provider
+// instance for ttl. Terraform reads data sources concurrently, so a request
+// for a key that is already being fetched waits for that response rather than
+// sending the same request again.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	// pending is set while the first request for the key is in flight, and
+	// ready is closed once it completes.
+	pending bool
+	ready   chan struct{}
+	resp    *cachedResponse
+	expires time.Time
+}
+
+// cachedResponse is the part of a response needed to serve it again.
+type cachedResponse struct {
+	status     string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+	url        *url.URL
+	tls        *tls.ConnectionState
+	// urlIndex is the index in urls of the URL the response came from.
+	urlIndex int
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// acquire returns the cached response for key. On a miss it returns nil and a
+// release function, which the caller must call with the response it fetched,
+// or nil if the response is not cacheable, to wake any requests waiting for
+// it. release may be called more than once; only the first call has effect.
+func (c *responseCache) acquire(ctx context.Context, key string) (*cachedResponse, func(*cachedResponse), error) {
+	for {
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		if ok && e.pending {
+			c.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-e.ready:
+				continue
+			}
+		}
+		if ok && time.Now().Before(e.expires) {
+			c.mu.Unlock()
+			return e.resp, nil, nil
+		}
+
+		c.purge(time.Now())
+		e = &cacheEntry{pending: true, ready: make(chan struct{})}
+		c.entries[key] = e
+		c.mu.Unlock()
+
+		var once sync.Once
+		release := func(resp *cachedResponse) {
+			once.Do(func() {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+
+				e.pending = false
+				if resp != nil {
+					e.resp = resp
+					e.expires = time.Now().Add(c.ttl)
+				} else {
+					delete(c.entries, key)
+				}
+				close(e.ready)
+			})
+		}
+
+		return nil, release, nil
+	}
+}
+
+// purge removes expired entries. c.mu must be held.
+func (c *responseCache) purge(now time.Time) {
+	for key, e := range c.entries {
+		if !e.pending && !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func newCachedResponse(resp *http.Response, body []byte) *cachedResponse {
+	return &cachedResponse{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		header:     resp.Header.Clone(),
+		body:       body,
+		url:        resp.Request.URL,
+		tls:        resp.TLS,
+	}
+}
+
+// response returns a new response holding the cached one.
+func (r *cachedResponse) response() *http.Response {
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.statusCode,
+		Proto:         r.proto,
+		Header:        r.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       &http.Request{URL: r.url},
+		TLS:           r.tls,
+	}
+}
+
+// isResponseCacheable reports whether a response may be stored: it must be
+// successful and must not be marked Cache-Control: no-store.
+func isResponseCacheable(resp *http.Response) bool {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false
+	}
+
+	for _, v := range resp.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// responseCacheKey identifies a request in the response cache by its method,
+// URL, headers, cookies and body. Whether redirects are followed and the
+// connection related arguments are included too, as they change the server
+// the request is sent to, how it is verified and which response is returned.
+// In particular a response fetched without block_private_ips is not served to
+// a request that would have been refused.
+func responseCacheKey(req *http.Request, body []byte, cookies []*http.Cookie, d *schema.ResourceData) string {
+	h := sha256.New()
+
+	writeCacheKeyParts(h, req.Method, req.URL.String(), req.Host, string(body))
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeCacheKeyParts(h, name)
+		writeCacheKeyParts(h, req.Header[name]...)
+	}
+
+	// The jar orders cookies by creation time, so they are sorted here.
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+	sort.Strings(pairs)
+	writeCacheKeyParts(h, pairs...)
+
+	writeCacheKeyParts(h, strconv.FormatBool(d.Get("follow_redirects").(bool)), transportKey(d))
+
+	// The URLs failed over to are part of the request too.
+	var urls []string
+	for _, u := range d.Get("urls").([]interface{}) {
+		urls = append(urls, u.(string))
+	}
+	writeCacheKeyParts(h, urls...)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeCacheKeyParts writes each part prefixed with its length, so that
+// different parts cannot produce the same key.
+func writeCacheKeyParts(h hash.Hash, parts ...string) {
+	fmt.Fprintf(h, "%d;", len(parts))
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+}
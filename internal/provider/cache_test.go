@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testCachedResponse(body string) *cachedResponse {
+	return &cachedResponse{
+		statusCode: http.StatusOK,
+		header:     http.Header{},
+		body:       []byte(body),
+		url:        &url.URL{Scheme: "http", Host: "example.com"},
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	c := newResponseCache(time.Hour)
+	ctx := context.Background()
+
+	cached, release, err := c.acquire(ctx, "a")
+	if err != nil || cached != nil || release == nil {
+		t.Fatalf("expected a miss, got %v, %v", cached, err)
+	}
+	release(testCachedResponse("1"))
+	release(testCachedResponse("2"))
+
+	cached, release, err = c.acquire(ctx, "a")
+	if err != nil || release != nil || cached == nil || string(cached.body) != "1" {
+		t.Fatalf("expected the first cached response, got %v, %v", cached, err)
+	}
+
+	// A response that is not stored leaves the key empty.
+	_, release, _ = c.acquire(ctx, "b")
+	release(nil)
+	if cached, release, _ = c.acquire(ctx, "b"); cached != nil || release == nil {
+		t.Fatalf("expected a miss, got %v", cached)
+	}
+	release(nil)
+}
+
+func TestResponseCache_expiry(t *testing.T) {
+	c := newResponseCache(10 * time.Millisecond)
+	ctx := context.Background()
+
+	_, release, _ := c.acquire(ctx, "a")
+	release(testCachedResponse("1"))
+
+	time.Sleep(20 * time.Millisecond)
+
+	cached, release, err := c.acquire(ctx, "a")
+	if err != nil || cached != nil || release == nil {
+		t.Fatalf("expected a miss after expiry, got %v, %v", cached, err)
+	}
+	release(nil)
+}
+
+func TestResponseCache_concurrent(t *testing.T) {
+	c := newResponseCache(time.Hour)
+
+	_, release, _ := c.acquire(context.Background(), "a")
+
+	results := make(chan *cachedResponse)
+	go func() {
+		cached, _, _ := c.acquire(context.Background(), "a")
+		results <- cached
+	}()
+
+	// The second reader waits for the first request to complete.
+	select {
+	case <-results:
+		t.Fatal("expected the second reader to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release(testCachedResponse("1"))
+
+	if cached := <-results; cached == nil || string(cached.body) != "1" {
+		t.Fatalf("expected the stored response, got %v", cached)
+	}
+
+	// A waiting reader gives up when its context is done.
+	_, _, _ = c.acquire(context.Background(), "b")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.acquire(ctx, "b"); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestIsResponseCacheable(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode   int
+		CacheControl string
+		Expected     bool
+	}{
+		"ok":           {StatusCode: 200, Expected: true},
+		"max age":      {StatusCode: 200, CacheControl: "public, max-age=60", Expected: true},
+		"no store":     {StatusCode: 200, CacheControl: "private, No-Store", Expected: false},
+		"not found":    {StatusCode: 404, Expected: false},
+		"not modified": {StatusCode: 304, Expected: false},
+		"server error": {StatusCode: 500, Expected: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.StatusCode, Header: http.Header{}}
+			if tc.CacheControl != "" {
+				resp.Header.Set("Cache-Control", tc.CacheControl)
+			}
+			if actual := isResponseCacheable(resp); actual != tc.Expected {
+				t.Fatalf("expected %t, got %t", tc.Expected, actual)
+			}
+		})
+	}
+}
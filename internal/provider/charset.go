@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// byteOrderMarks are the byte order marks a body may start with. They take
+// precedence over the charset parameter of the Content-Type, as in browsers.
+var byteOrderMarks = []struct {
+	bom     []byte
+	charset string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, "utf-8"},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeBody converts body to UTF-8 from the character set given by its byte
+// order mark or, without one, the charset parameter of contentType. The byte
+// order mark is removed. It returns the WHATWG name of the character set, or
+// "" when none is given, in which case body is returned as it is.
+func decodeBody(contentType string, body []byte) (string, []byte, error) {
+	label := ""
+	text := body
+	for _, m := range byteOrderMarks {
+		if bytes.HasPrefix(body, m.bom) {
+			label = m.charset
+			text = body[len(m.bom):]
+			break
+		}
+	}
+
+	if label == "" {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			label = params["charset"]
+		}
+	}
+
+	if label == "" {
+		return "", body, nil
+	}
+
+	// Labels are resolved as by browsers, so iso-8859-1 and us-ascii are read
+	// as windows-1252, their superset.
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return "", body, fmt.Errorf("unsupported charset %q", label)
+	}
+
+	charset, err := htmlindex.Name(enc)
+	if err != nil {
+		return "", body, fmt.Errorf("unsupported charset %q", label)
+	}
+
+	if charset == "utf-8" {
+		return charset, text, nil
+	}
+
+	text, err = enc.NewDecoder().Bytes(text)
+	if err != nil {
+		return "", body, fmt.Errorf("decoding %s: %s", charset, err)
+	}
+
+	return charset, text, nil
+}
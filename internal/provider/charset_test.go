@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestDecodeBody(t *testing.T) {
+	cases := map[string]struct {
+		ContentType   string
+		Body          []byte
+		ExpectCharset string
+		ExpectText    string
+		ExpectErr     bool
+	}{
+		"no charset": {
+			ContentType: "text/plain",
+			Body:        []byte("caf\xc3\xa9"),
+			ExpectText:  "caf\xc3\xa9",
+		},
+		"no content type": {
+			Body:       []byte("1.0.0"),
+			ExpectText: "1.0.0",
+		},
+		"utf-8": {
+			ContentType:   "text/plain; charset=UTF-8",
+			Body:          []byte("caf\xc3\xa9"),
+			ExpectCharset: "utf-8",
+			ExpectText:    "caf\xc3\xa9",
+		},
+		"utf-16": {
+			ContentType:   "application/json; charset=utf-16",
+			Body:          utf16LE(`"café"`),
+			ExpectCharset: "utf-16le",
+			ExpectText:    `"café"`,
+		},
+		"utf-16be": {
+			ContentType:   "text/plain; charset=utf-16be",
+			Body:          []byte{0x00, 'c', 0x00, 'a', 0x00, 'f', 0x00, 0xe9},
+			ExpectCharset: "utf-16be",
+			ExpectText:    "café",
+		},
+		"iso-8859-1": {
+			ContentType:   "text/plain; charset=ISO-8859-1",
+			Body:          []byte("caf\xe9"),
+			ExpectCharset: "windows-1252",
+			ExpectText:    "café",
+		},
+		"shift_jis": {
+			ContentType:   "text/plain; charset=Shift_JIS",
+			Body:          []byte{0x93, 0xfa, 0x96, 0x7b},
+			ExpectCharset: "shift_jis",
+			ExpectText:    "日本",
+		},
+		"utf-8 byte order mark": {
+			ContentType:   "text/plain",
+			Body:          []byte("\xef\xbb\xbf1.0.0"),
+			ExpectCharset: "utf-8",
+			ExpectText:    "1.0.0",
+		},
+		"utf-16le byte order mark": {
+			ContentType:   "text/plain",
+			Body:          append([]byte{0xff, 0xfe}, utf16LE("1.0.0")...),
+			ExpectCharset: "utf-16le",
+			ExpectText:    "1.0.0",
+		},
+		"byte order mark overrides charset": {
+			ContentType:   "text/plain; charset=iso-8859-1",
+			Body:          []byte{0xfe, 0xff, 0x00, '1'},
+			ExpectCharset: "utf-16be",
+			ExpectText:    "1",
+		},
+		"unsupported charset": {
+			ContentType: "text/plain; charset=x-unknown",
+			Body:        []byte("1.0.0"),
+			ExpectText:  "1.0.0",
+			ExpectErr:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			charset, text, err := decodeBody(tc.ContentType, tc.Body)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if charset != tc.ExpectCharset {
+				t.Errorf("expected charset %q, got %q", tc.ExpectCharset, charset)
+			}
+			if string(text) != tc.ExpectText {
+				t.Errorf("expected text %q, got %q", tc.ExpectText, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// circuitBreaker tracks consecutive failed requests per host. Once threshold
+// requests to a host fail in a row its circuit opens, and requests to it fail
+// at once for the cooldown. A single request is then let through to probe the
+// host: success closes the circuit while failure opens it again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	hosts     map[string]*circuitState
+}
+
+// circuitState is the state of the circuit of one host.
+type circuitState struct {
+	failures int
+	// openUntil is when the next request may probe the host, zero while the
+	// circuit is closed.
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*circuitState),
+	}
+}
+
+// circuitOpenError is returned for a request not sent because the circuit of
+// its host is open.
+type circuitOpenError struct {
+	host      string
+	failures  int
+	openUntil time.Time
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %s is open after %d consecutive failed requests, requests fail without being sent until %s",
+		e.host, e.failures, e.openUntil.UTC().Format(time.RFC3339))
+}
+
+// allow returns an error if the circuit of host is open. When the cooldown
+// has passed, the caller is let through to probe the host and the circuit
+// stays open to others for another cooldown.
+func (b *circuitBreaker) allow(host string, now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.hosts[host]
+	if !ok || state.openUntil.IsZero() {
+		return nil
+	}
+
+	if now.Before(state.openUntil) {
+		return &circuitOpenError{host: host, failures: state.failures, openUntil: state.openUntil}
+	}
+
+	state.openUntil = now.Add(b.cooldown)
+
+	return nil
+}
+
+// record counts the outcome of a request to host, opening its circuit when
+// threshold requests in a row have failed.
+func (b *circuitBreaker) record(host string, failed bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		delete(b.hosts, host)
+		return
+	}
+
+	state, ok := b.hosts[host]
+	if !ok {
+		state = &circuitState{}
+		b.hosts[host] = state
+	}
+
+	state.failures++
+	if state.failures >= b.threshold {
+		state.openUntil = now.Add(b.cooldown)
+	}
+}
+
+// circuitBreakerTransport fails requests to hosts whose circuit is open and
+// records the outcome of the others. Connection errors and 5xx responses are
+// failures, as they are for retries.
+type circuitBreakerTransport struct {
+	base    http.RoundTripper
+	breaker *circuitBreaker
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+
+	if err := t.breaker.allow(host, time.Now()); err != nil {
+		// A RoundTripper must close the request body, even on errors.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+
+	// A request cancelled by Terraform says nothing about the host.
+	if !errors.Is(err, context.Canceled) {
+		t.breaker.record(host, err != nil || resp.StatusCode >= 500, time.Now())
+	}
+
+	return resp, err
+}
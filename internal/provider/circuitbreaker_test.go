@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	start := time.Unix(0, 0)
+	b := newCircuitBreaker(2, time.Second)
+
+	steps := []struct {
+		At        time.Duration
+		Host      string
+		Failed    bool
+		ExpectErr bool
+	}{
+		// A success resets the count of consecutive failures.
+		{At: 0, Host: "a", Failed: true},
+		{At: 0, Host: "a", Failed: false},
+		{At: 0, Host: "a", Failed: true},
+		{At: 0, Host: "a", Failed: true},
+		// The circuit is open, but only for the failing host.
+		{At: 0, Host: "a", ExpectErr: true},
+		{At: 0, Host: "b", Failed: false},
+		{At: 500 * time.Millisecond, Host: "a", ExpectErr: true},
+		// After the cooldown one request probes the host while others still
+		// fail, and its failure opens the circuit again.
+		{At: time.Second, Host: "a", Failed: true},
+		{At: time.Second, Host: "a", ExpectErr: true},
+		{At: 1500 * time.Millisecond, Host: "a", ExpectErr: true},
+		// A successful probe closes the circuit.
+		{At: 2 * time.Second, Host: "a", Failed: false},
+		{At: 2 * time.Second, Host: "a", Failed: true},
+	}
+
+	for i, step := range steps {
+		now := start.Add(step.At)
+
+		err := b.allow(step.Host, now)
+		if step.ExpectErr {
+			if err == nil {
+				t.Fatalf("step %d: expected the circuit of %s to be open", i, step.Host)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %s", i, err)
+		}
+
+		b.record(step.Host, step.Failed, now)
+	}
+}
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &circuitBreakerTransport{
+			base:    http.DefaultTransport,
+			breaker: newCircuitBreaker(2, time.Minute),
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Retrying stops once the circuit opens.
+	start := time.Now()
+	resp, attempts, err := doRequestWithRetry(context.Background(), client, req, retryConfig{attempts: 5, minDelay: 10 * time.Millisecond, maxDelay: 10 * time.Millisecond})
+
+	var circuitErr *circuitOpenError
+	if !errors.As(err, &circuitErr) {
+		t.Fatalf("expected the circuit to be open, got response %v and error %v", resp, err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests to reach the server, got %d", requests)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the request to fail promptly, took %s", elapsed)
+	}
+}
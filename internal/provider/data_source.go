@@ -3,31 +3,107 @@ package provider
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
+// urlSchemes are the URL schemes accepted by the provider. Requests are sent
+// over a Unix domain socket with unix_socket rather than a scheme of their own.
+var urlSchemes = []string{"http", "https"}
+
+// requestMethods are the request methods accepted by the provider.
+var requestMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
 func dataSource() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceRead,
 
 		Schema: map[string]*schema.Schema{
 			"url": {
-				Type:     schema.TypeString,
-				Required: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"url", "urls"},
+				ValidateFunc: validateURL(urlSchemes),
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
 
+			"urls": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MinItems:      1,
+				ExactlyOneOf:  []string{"url", "urls"},
+				ConflictsWith: []string{"wait_for"},
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateURL(urlSchemes),
+				},
+				Description: "URLs tried in order until one responds without a connection error or 5xx status. An alternative to url.",
+			},
+
+			"resolved_url_index": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The index in urls of the URL the response was received from. Always 0 when url is set.",
+			},
+
+			"skip_if": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the request is skipped. When true no request is sent, body is set to skip_body and status_code to 0.",
+			},
+
+			"skip_body": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "",
+				Description: "The body returned in place of a response when skip_if is true.",
+			},
+
+			"validate_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the request is only built and checked, without being sent. sent_request_headers and request_dump describe the request that would be sent.",
+			},
+
+			"validated": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the request was built and checked without being sent, because validate_only is true.",
+			},
+
 			"request_headers": {
 				Type:     schema.TypeMap,
 				Optional: true,
@@ -36,14 +112,496 @@ func dataSource() *schema.Resource {
 				},
 			},
 
+			"cookies": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Cookies sent with the request. Cookies set by responses are kept across redirects.",
+			},
+
+			"user_agent": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The User-Agent header sent with the request. Defaults to terraform-provider-http/<version>.",
+			},
+
+			"user_agent_append": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the provider's default User-Agent is appended to user_agent rather than replaced by it.",
+			},
+
+			"accept": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Accept header sent with the request.",
+			},
+
+			"accept_encoding": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Accept-Encoding header sent with the request. The response body is then not decompressed.",
+			},
+
+			"referer": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The Referer header sent with the request. A Referer entry in request_headers takes precedence.",
+			},
+
+			"origin": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateOrigin(urlSchemes),
+				Description:  "The Origin header sent with the request, such as https://example.com. An Origin entry in request_headers takes precedence.",
+			},
+
+			"host_override": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The Host header sent with the request. Defaults to the host of the URL, which is still the one connected to.",
+			},
+
+			"request_id_header": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The name of a header, such as X-Request-ID, sent with a generated UUID to trace the request. A value set in request_headers is sent instead.",
+			},
+
+			"request_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value of the request_id_header header sent with the request.",
+			},
+
+			"request_headers_list": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "A request header sent once for each of its values.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The header name.",
+						},
+
+						"values": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "The header values, each sent as a separate header line.",
+						},
+					},
+				},
+			},
+
+			"graphql": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"request_method", "request_body", "request_body_file", "request_body_json", "request_body_base64", "body_template", "multipart", "request_content_type"},
+				Description:   "Send a GraphQL query as a JSON POST request.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"query": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The GraphQL query document.",
+						},
+
+						"variables": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The query variables as a JSON object, for example built with jsonencode.",
+						},
+
+						"operation_name": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The operation to run when the query contains several.",
+						},
+					},
+				},
+			},
+
+			"basic_auth": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"bearer_token", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "digest_auth", "kerberos_auth", "jwt"},
+				Description:   "Credentials for HTTP basic authentication.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The username for basic authentication.",
+						},
+
+						"password": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The password for basic authentication.",
+						},
+					},
+				},
+			},
+
+			"bearer_token": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				ConflictsWith: []string{"basic_auth", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "digest_auth", "kerberos_auth", "jwt"},
+				Description:   "A token sent in the Authorization header using the Bearer scheme.",
+			},
+
+			"aws_sigv4": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "digest_auth", "kerberos_auth", "jwt", "multipart"},
+				Description:   "Sign the request with AWS Signature Version 4.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"region": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The AWS region the request is signed for.",
+						},
+
+						"service": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The AWS service the request is signed for, such as execute-api.",
+						},
+
+						"access_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							RequiredWith: []string{"aws_sigv4.0.secret_key"},
+							Description:  "The AWS access key. Defaults to the AWS default credential chain.",
+						},
+
+						"secret_key": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							RequiredWith: []string{"aws_sigv4.0.access_key"},
+							Description:  "The AWS secret key.",
+						},
+
+						"session_token": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Sensitive:   true,
+							Description: "The AWS session token for temporary credentials.",
+						},
+					},
+				},
+			},
+
+			"oauth2_client_credentials": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "gcp_id_token", "ntlm_auth", "digest_auth", "kerberos_auth", "jwt"},
+				Description:   "Obtain a token with the OAuth2 client credentials grant and send it in the Authorization header.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"token_url": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The URL of the token endpoint.",
+						},
+
+						"client_id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The client ID.",
+						},
+
+						"client_secret": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The client secret.",
+						},
+
+						"scopes": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "The scopes to request.",
+						},
+					},
+				},
+			},
+
+			"gcp_id_token": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "oauth2_client_credentials", "ntlm_auth", "digest_auth", "kerberos_auth", "jwt"},
+				Description:   "Send a Google-signed ID token from the Application Default Credentials as a bearer token.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"audience": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The audience the ID token is issued for, such as the URL of a Cloud Run service.",
+						},
+					},
+				},
+			},
+
+			"jwt": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "digest_auth", "kerberos_auth"},
+				Description:   "Send a JWT signed by the provider as a bearer token.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"signing_key": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The PEM encoded private key the JWT is signed with, or the shared secret for HS256.",
+						},
+
+						"algorithm": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "RS256",
+							ValidateFunc: validateStringInSlice(jwtAlgorithmNames),
+							Description:  "The signing algorithm, one of RS256, HS256 or ES256.",
+						},
+
+						"key_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The kid header identifying the signing key to the server.",
+						},
+
+						"claims": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "The claims of the JWT, such as iss and sub. exp, iat and nbf are sent as numbers.",
+						},
+
+						"ttl_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      300000,
+							ValidateFunc: validateIntAtLeast(1000),
+							Description:  "How long the JWT is valid for in milliseconds, setting exp unless it is given in claims.",
+						},
+					},
+				},
+			},
+
+			"ntlm_auth": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "digest_auth", "kerberos_auth", "jwt", "disable_keep_alives"},
+				Description:   "Authenticate with NTLM when the server requests it.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The user name.",
+						},
+
+						"password": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The password.",
+						},
+
+						"domain": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The domain of the user.",
+						},
+					},
+				},
+			},
+
+			"digest_auth": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "kerberos_auth", "jwt"},
+				Description:   "Authenticate with HTTP digest authentication when the server requests it.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The user name.",
+						},
+
+						"password": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The password.",
+						},
+					},
+				},
+			},
+
+			"kerberos_auth": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"basic_auth", "bearer_token", "aws_sigv4", "oauth2_client_credentials", "gcp_id_token", "ntlm_auth", "digest_auth", "jwt"},
+				Description:   "Authenticate with Kerberos through SPNEGO (HTTP Negotiate) when the server requests it.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The user name, without the realm. Required with password or keytab_file.",
+						},
+
+						"realm": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The realm of the user. Required with password or keytab_file, and defaults to the realm of the ccache_file otherwise.",
+						},
+
+						"password": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Sensitive:    true,
+							ExactlyOneOf: []string{"kerberos_auth.0.password", "kerberos_auth.0.keytab_file", "kerberos_auth.0.ccache_file"},
+							RequiredWith: []string{"kerberos_auth.0.username", "kerberos_auth.0.realm"},
+							Description:  "The password of the user.",
+						},
+
+						"keytab_file": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ExactlyOneOf: []string{"kerberos_auth.0.password", "kerberos_auth.0.keytab_file", "kerberos_auth.0.ccache_file"},
+							RequiredWith: []string{"kerberos_auth.0.username", "kerberos_auth.0.realm"},
+							Description:  "The path of a keytab holding the keys of the user.",
+						},
+
+						"ccache_file": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ExactlyOneOf: []string{"kerberos_auth.0.password", "kerberos_auth.0.keytab_file", "kerberos_auth.0.ccache_file"},
+							Description:  "The path of a credential cache holding a ticket-granting ticket, as written by kinit.",
+						},
+
+						"krb5_conf": {
+							Type:          schema.TypeString,
+							Optional:      true,
+							ConflictsWith: []string{"kerberos_auth.0.kdcs"},
+							Description:   "The Kerberos configuration in krb5.conf format. Defaults to the file named by the KRB5_CONFIG environment variable or /etc/krb5.conf.",
+						},
+
+						"kdcs": {
+							Type:          schema.TypeList,
+							Optional:      true,
+							Elem:          &schema.Schema{Type: schema.TypeString},
+							ConflictsWith: []string{"kerberos_auth.0.krb5_conf"},
+							Description:   "The KDCs of the realm as host:port, used instead of a krb5.conf.",
+						},
+
+						"spn": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The service principal name of the server. Defaults to HTTP/ followed by the host of the URL.",
+						},
+					},
+				},
+			},
+
+			"hmac_signature": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"multipart"},
+				Description:   "Send an HMAC of the request body in a request header.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"secret": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Sensitive:   true,
+							Description: "The key the HMAC is computed with.",
+						},
+
+						"algorithm": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "sha256",
+							ValidateFunc: validateStringInSlice(hmacAlgorithmNames),
+							Description:  "The hash algorithm, one of sha1, sha256 or sha512.",
+						},
+
+						"header_name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The request header the signature is sent in.",
+						},
+
+						"prefix": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "A string prepended to the hex encoded signature, such as sha256=.",
+						},
+					},
+				},
+			},
+
+			"query_parameters": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "A map of query parameters to URL-encode and append to the URL's query string.",
+			},
+
+			"path_segments": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validatePathSegment(),
+				},
+				Description: "Path segments to URL-encode and append to the URL's path.",
+			},
+
 			"request_method": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Default:     "GET",
-				Description: "Request method type to call the API with.",
+				Default:      "GET",
+				ValidateFunc: validateStringInSlice(requestMethods),
+				Description:  "Request method type to call the API with.",
 			},
 
 			"request_body": {
@@ -52,7 +610,102 @@ func dataSource() *schema.Resource {
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Default: nil,
+				Default:       nil,
+				ConflictsWith: []string{"request_body_file", "request_body_json", "request_body_base64", "body_template", "multipart", "graphql"},
+			},
+
+			"request_body_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"request_body", "request_body_json", "request_body_base64", "body_template", "multipart", "graphql"},
+				Description:   "Path to a file whose contents are sent as the request body.",
+			},
+
+			"request_body_json": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateJSON(),
+				ConflictsWith: []string{"request_body", "request_body_file", "request_body_base64", "body_template", "multipart", "graphql"},
+				Description:   "A JSON document sent as the request body with Content-Type application/json.",
+			},
+
+			"request_body_base64": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateBase64(),
+				ConflictsWith: []string{"request_body", "request_body_file", "request_body_json", "body_template", "multipart", "graphql"},
+				Description:   "A base64 encoded request body, decoded and sent as raw bytes. Suited to binary payloads.",
+			},
+
+			"body_template": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateBodyTemplate(),
+				ConflictsWith: []string{"request_body", "request_body_file", "request_body_json", "request_body_base64", "multipart", "graphql"},
+				Description:   "A Go text/template rendered with body_vars and sent as the request body.",
+			},
+
+			"body_vars": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				RequiredWith: []string{"body_template"},
+				Description:  "The variables available to body_template, referenced as {{.name}}.",
+			},
+
+			"request_content_type": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateMediaType(),
+				ConflictsWith: []string{"multipart", "graphql"},
+				Description:   "The Content-Type header sent with the request. It takes precedence over request_headers and the type set for request_body_json.",
+			},
+
+			"allow_body_on_get": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the request body is sent with a GET request. By default it is left out with a warning.",
+			},
+
+			"multipart": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"request_body", "request_body_file", "request_body_json", "request_body_base64", "body_template", "aws_sigv4", "hmac_signature", "graphql", "request_content_type"},
+				Description:   "Send a multipart/form-data request body built from the given fields.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "The form field name.",
+									},
+
+									"value": {
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "The value of a plain form field.",
+									},
+
+									"file_path": {
+										Type:        schema.TypeString,
+										Optional:    true,
+										Description: "Path to a file uploaded as the field's content.",
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 
 			"body": {
@@ -71,77 +724,1752 @@ func dataSource() *schema.Resource {
 				},
 			},
 
-			"skip_tls_verify": {
-				Type:     schema.TypeBool,
-				Optional: true,
-				Default:  false,
+			"content_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The Content-Type response header as sent by the server.",
 			},
-		},
-	}
-}
 
-func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
-	url := d.Get("url").(string)
-	headers := d.Get("request_headers").(map[string]interface{})
-	method := d.Get("request_method").(string)
-	body := []byte(d.Get("request_body").(string))
-	skip_tls_verify := d.Get("skip_tls_verify").(bool)
+			"content_type_mime": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The media type of the Content-Type response header in lower case, without parameters such as charset.",
+			},
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: skip_tls_verify},
-	}
-	client := &http.Client{Transport: tr}
+			"charset": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The character set body was converted to UTF-8 from, taken from a byte order mark or the charset parameter of the Content-Type.",
+			},
 
-	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(body))
-	if err != nil {
-		return append(diags, diag.Errorf("Error creating request: %s", err)...)
-	}
+			"response_trailers": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The trailers sent by the server after the response body.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 
-	for name, value := range headers {
-		req.Header.Set(name, value.(string))
-	}
+			"body_base64": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The raw body of the HTTP response, base64 encoded.",
+			},
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return append(diags, diag.Errorf("Error making request: %s", err)...)
-	}
+			"response_headers_lower": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The response headers keyed by lowercase header name.",
+			},
 
-	defer resp.Body.Close()
+			"response_headers_list": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The response headers sorted by name, keeping each value of a repeated header such as Set-Cookie.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The header name.",
+						},
 
-	if resp.StatusCode != 200 {
-		return append(diags, diag.Errorf("HTTP request error. Response code: %d", resp.StatusCode)...)
-	}
+						"values": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "The header values in the order they were received.",
+						},
+					},
+				},
+			},
 
-	contentType := resp.Header.Get("Content-Type")
-	if contentType == "" || isContentTypeText(contentType) == false {
-		diags = append(diags, diag.Diagnostic{
-			Severity: diag.Warning,
-			Summary:  fmt.Sprintf("Content-Type is not recognized as a text type, got %q", contentType),
-			Detail:   "If the content is binary data, Terraform may not properly handle the contents of the response.",
-		})
-	}
+			"response_body_json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The response body re-encoded as normalized JSON when the Content-Type is JSON.",
+			},
 
-	bytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return append(diags, diag.FromErr(err)...)
-	}
+			"response_body_xml": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The response body converted from XML to JSON when the Content-Type is XML.",
+			},
 
-	responseHeaders := make(map[string]string)
-	for k, v := range resp.Header {
-		// Concatenate according to RFC2616
-		// cf. https://www.w3.org/Protocols/rfc2616/rfc2616-sec4.html#sec4.2
-		responseHeaders[k] = strings.Join(v, ", ")
-	}
+			"body_url_decoded": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The response body with percent-encoding decoded. Empty if the body is not validly encoded.",
+			},
 
-	d.Set("body", string(bytes))
-	if err = d.Set("response_headers", responseHeaders); err != nil {
-		return append(diags, diag.Errorf("Error setting HTTP response headers: %s", err)...)
-	}
+			"response_body_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"json_paths", "head_bytes"},
+				Description:   "Path of a file the response body is written to instead of being stored in body.",
+			},
 
-	// set ID as something more stable than time
-	d.SetId(url)
+			"body_sha256": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The hex encoded SHA-256 checksum of the raw response body.",
+			},
 
-	return diags
+			"json_paths": {
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ConflictsWith: []string{"response_body_file"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "A map of names to JSONPath expressions evaluated against the JSON response body.",
+			},
+
+			"json_schema": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateJSON(),
+				ConflictsWith: []string{"json_schema_file", "response_body_file"},
+				Description:   "A JSON Schema the response body must match when it is JSON.",
+			},
+
+			"json_schema_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"json_schema", "response_body_file"},
+				Description:   "Path to a file holding a JSON Schema the response body must match when it is JSON.",
+			},
+
+			"json_paths_strict": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether a path in json_paths that matches nothing is an error rather than an empty string.",
+			},
+
+			"json_values": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The values selected by json_paths, keyed by the same names.",
+			},
+
+			"body_regex": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"response_body_file"},
+				ValidateFunc:  validateRegexp(),
+				Description:   "A regular expression matched against the response body, whose first capture group is set in body_match.",
+			},
+
+			"body_regex_strict": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether a body_regex that matches nothing is an error rather than an empty body_match.",
+			},
+
+			"body_match": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The first capture group of the body_regex match, or the whole match if the pattern has no groups.",
+			},
+
+			"body_captures": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The capture groups of the body_regex match, in order.",
+			},
+
+			"status_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The HTTP response status code.",
+			},
+
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The HTTP response status line without the protocol, such as 200 OK.",
+			},
+
+			"response_time_ms": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The time taken to send the request and read the full response body, in milliseconds.",
+			},
+
+			"debug": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the request and response are recorded in request_dump and response_dump.",
+			},
+
+			"redact_response_headers": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Response headers left out of response_headers, response_headers_lower and response_headers_list, matched case-insensitively.",
+			},
+
+			"debug_redact_headers": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Additional headers whose values are redacted in request_dump, response_dump and sent_request_headers.",
+			},
+
+			"sent_request_headers": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The request headers sent after merging default_headers, request headers and authentication, with credentials redacted.",
+			},
+
+			"request_dump": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The request as sent on the wire, when debug is enabled.",
+			},
+
+			"response_dump": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The response as received on the wire, when debug is enabled.",
+			},
+
+			"content_length": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The length of the response body in bytes, from the Content-Length header or the number of bytes read.",
+			},
+
+			"from_cache": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the response was served from the provider's response cache.",
+			},
+
+			"final_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The URL the response was received from, after following any redirects.",
+			},
+
+			"expected_content_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "A Content-Type the response must have. The response Content-Type must equal or start with this value.",
+			},
+
+			"etag": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ETag response header.",
+			},
+
+			"used_tls": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the final response, after redirects, was received over TLS.",
+			},
+
+			"response_protocol": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The protocol of the final response, after redirects, such as HTTP/1.1 or HTTP/2.0.",
+			},
+
+			"tls_cert_not_after": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The expiry time of the server's TLS certificate in RFC 3339 format. Empty if the connection did not use TLS.",
+			},
+
+			"tls_cert_issuer": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The distinguished name of the issuer of the server's TLS certificate. Empty if the connection did not use TLS.",
+			},
+
+			"tls_cert_subject": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The distinguished name of the subject of the server's TLS certificate. Empty if the connection did not use TLS.",
+			},
+
+			"if_none_match": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "An entity tag sent in the If-None-Match request header. A 304 Not Modified response is then accepted.",
+			},
+
+			"if_modified_since": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateHTTPTime(),
+				Description:  "A time in RFC 1123 or RFC 3339 format sent in the If-Modified-Since request header. A 304 Not Modified response is then accepted.",
+			},
+
+			"expected_status_codes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				Description: "A list of response status codes that are treated as successful. Defaults to any 2xx code.",
+			},
+
+			"skip_content_type_warning": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the warning for a Content-Type that is not recognized as text is suppressed.",
+			},
+
+			"fail_on_empty_body": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether an empty response body results in an error.",
+			},
+
+			"request_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "The request timeout in milliseconds. Defaults to the provider's timeout_ms, or no timeout.",
+			},
+
+			"dial_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "How long to wait for a connection to be established in milliseconds, including DNS resolution. Defaults to no limit beyond request_timeout_ms.",
+			},
+
+			"tls_handshake_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "How long to wait for the TLS handshake in milliseconds. Defaults to no limit beyond request_timeout_ms.",
+			},
+
+			"expect_continue_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "Send the Expect: 100-continue header with a request body, and wait this long in milliseconds for the server to accept it before sending the body anyway. Defaults to sending the body at once.",
+			},
+
+			"max_response_body_bytes": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "The maximum size of the response body in bytes. Defaults to no limit.",
+			},
+
+			"head_bytes": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Default:       0,
+				ValidateFunc:  validateIntAtLeast(0),
+				ConflictsWith: []string{"response_body_file"},
+				Description:   "Read only the first head_bytes bytes of the response body and close the connection. Defaults to reading the whole body.",
+			},
+
+			"follow_redirects": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether redirect responses are followed. When false, the redirect response itself is returned.",
+			},
+
+			"disable_keep_alives": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether a new connection is used for every request, including retries, instead of reusing idle connections.",
+			},
+
+			"disable_http2": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether HTTP/2 is disabled, forcing HTTP/1.1 for HTTPS requests.",
+			},
+
+			"retry": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Retry the request on connection errors and 5xx responses.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"attempts": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      3,
+							ValidateFunc: validateIntAtLeast(0),
+							Description:  "The number of times the request is retried after the initial attempt.",
+						},
+
+						"min_delay_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1000,
+							ValidateFunc: validateIntAtLeast(0),
+							Description:  "The minimum delay between retries in milliseconds.",
+						},
+
+						"max_delay_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30000,
+							ValidateFunc: validateIntAtLeast(0),
+							Description:  "The maximum delay between retries in milliseconds.",
+						},
+					},
+				},
+			},
+
+			"retry_status_codes": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+				RequiredWith: []string{"retry"},
+				Description:  "Additional response status codes that are retried, such as 409 for a transient lock.",
+			},
+
+			"wait_for": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"urls"},
+				Description:   "Poll the URL until the response matches a condition.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"status_code": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Description: "The status code to wait for.",
+						},
+
+						"body_regex": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateRegexp(),
+							Description:  "A regular expression the response body must match.",
+						},
+
+						"timeout_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      60000,
+							ValidateFunc: validateIntAtLeast(0),
+							Description:  "How long to wait for the condition in milliseconds.",
+						},
+
+						"interval_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1000,
+							ValidateFunc: validateIntAtLeast(0),
+							Description:  "The delay between requests in milliseconds.",
+						},
+					},
+				},
+			},
+
+			"paginate": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"wait_for", "response_body_file", "head_bytes"},
+				Description:   "Follow the next links of Link response headers and combine the pages into body.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_pages": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      10,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "The maximum number of pages fetched, including the first.",
+						},
+
+						"mode": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      paginateModeConcatJSONArrays,
+							ValidateFunc: validateStringInSlice(paginateModes),
+							Description:  "How the pages are combined: concat-json-arrays or collect-bodies.",
+						},
+					},
+				},
+			},
+
+			"sse": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"wait_for", "paginate", "response_body_file", "head_bytes"},
+				Description:   "Read the response as a stream of server-sent events, collected into sse_events.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"terminate_on_event": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "The event type that ends the stream. Defaults to reading until the server closes it or timeout_ms passes.",
+						},
+
+						"timeout_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30000,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "How long to read the stream for in milliseconds.",
+						},
+					},
+				},
+			},
+
+			"sse_events": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The events read from the stream when sse is set.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The last event ID sent before the event.",
+						},
+
+						"event": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The event type, message unless the server set one.",
+						},
+
+						"data": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The event data, with the lines of multi-line data joined by newlines.",
+						},
+					},
+				},
+			},
+
+			"pages_fetched": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of pages fetched when paginate is set.",
+			},
+
+			"proxy_url": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"unix_socket"},
+				Description:   "The URL of a proxy server (http, https or socks5) to send the request through. Defaults to the proxy configured in the environment.",
+			},
+
+			"no_proxy": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				RequiredWith: []string{"proxy_url"},
+				Description:  "Hosts, domains and CIDR blocks that are connected to directly instead of through proxy_url.",
+			},
+
+			"unix_socket": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"proxy_url", "resolve", "local_address"},
+				Description:   "Path to a Unix domain socket to connect to instead of the host in the URL.",
+			},
+
+			"resolve": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ConflictsWith: []string{"unix_socket"},
+				Description:   "A map of host:port pairs to the IP addresses connected to in their place.",
+			},
+
+			"local_address": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validateIPAddress(),
+				ConflictsWith: []string{"unix_socket"},
+				Description:   "The local IP address connections are made from, such as one of the addresses of a multi-homed host.",
+			},
+
+			"block_private_ips": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether connections to loopback, private and link-local addresses are refused, including those for redirects.",
+			},
+
+			"allowed_private_cidrs": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateCIDR(),
+				},
+				RequiredWith: []string{"block_private_ips"},
+				Description:  "CIDR blocks that may be connected to despite block_private_ips.",
+			},
+
+			"skip_tls_verify": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				Deprecated:    "Use insecure_skip_verify instead.",
+				ConflictsWith: []string{"insecure_skip_verify", "pin_sha256"},
+			},
+
+			"insecure_skip_verify": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"skip_tls_verify", "pin_sha256"},
+				Description:   "Disables verification of the server's certificate chain and host name. This is insecure and should only be used for testing.",
+			},
+
+			"ca_cert_pem": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"pin_sha256"},
+				Description:   "One or more PEM encoded CA certificates used to verify the server certificate.",
+			},
+
+			"pin_sha256": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validatePinSHA256(),
+				ConflictsWith: []string{"insecure_skip_verify", "skip_tls_verify", "ca_cert_pem"},
+				Description:   "The SHA-256 fingerprint, in hex or base64, of the public key or the whole certificate the server must present. It replaces verification of the certificate chain and host name.",
+			},
+
+			"tls_min_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateStringInSlice(tlsVersionNames),
+				Description:  "The minimum TLS version to negotiate, one of 1.0, 1.1, 1.2 or 1.3.",
+			},
+
+			"tls_max_version": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateStringInSlice(tlsVersionNames),
+				Description:  "The maximum TLS version to negotiate, one of 1.0, 1.1, 1.2 or 1.3.",
+			},
+
+			"tls_cipher_suites": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateStringInSlice(tlsCipherSuiteNames),
+				},
+				Description: "The cipher suites offered for TLS 1.2 and earlier, by name such as TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256. TLS 1.3 suites cannot be configured.",
+			},
+
+			"client_cert_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_key_pem"},
+				Description:  "PEM encoded client certificate used for mutual TLS authentication.",
+			},
+
+			"client_key_pem": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"client_cert_pem"},
+				Description:  "PEM encoded private key for the client certificate.",
+			},
+		},
+	}
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) (diags diag.Diagnostics) {
+	rawURLs := []string{d.Get("url").(string)}
+	if v := d.Get("urls").([]interface{}); len(v) > 0 {
+		rawURLs = make([]string, len(v))
+		for i, u := range v {
+			rawURLs[i] = u.(string)
+		}
+	}
+
+	// A skipped data source keeps its attributes, so references to it remain
+	// valid, unlike one removed with count.
+	if d.Get("skip_if").(bool) {
+		d.Set("body", d.Get("skip_body").(string))
+		d.Set("status_code", 0)
+		d.Set("status", "")
+		d.SetId(rawURLs[0])
+		return diags
+	}
+
+	headers := d.Get("request_headers").(map[string]interface{})
+	method := d.Get("request_method").(string)
+	expectedStatusCodes := d.Get("expected_status_codes").([]interface{})
+	ifNoneMatch := d.Get("if_none_match").(string)
+	ifModifiedSince := d.Get("if_modified_since").(string)
+	expectedContentType := d.Get("expected_content_type").(string)
+	responseBodyFile := d.Get("response_body_file").(string)
+	config := meta.(*providerConfig)
+	requestTimeout := d.Get("request_timeout_ms").(int)
+	if requestTimeout == 0 {
+		requestTimeout = config.timeoutMs
+	}
+	retry := expandRetryConfig(d.Get("retry").([]interface{}))
+	for _, v := range d.Get("retry_status_codes").([]interface{}) {
+		retry.statusCodes = append(retry.statusCodes, v.(int))
+	}
+	maxResponseBodyBytes := d.Get("max_response_body_bytes").(int)
+
+	if retry.minDelay > retry.maxDelay {
+		return append(diags, diag.Errorf("retry min_delay_ms must be less than or equal to max_delay_ms")...)
+	}
+
+	wait, err := expandWaitConfig(d.Get("wait_for").([]interface{}))
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	paginate := expandPaginateConfig(d.Get("paginate").([]interface{}))
+
+	sse := expandSSEConfig(d.Get("sse").([]interface{}))
+
+	jsonSchema, err := compileJSONSchema(d)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	tlsConfig, err := newTLSConfig(d, config)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	// With pin_sha256 the certificate is verified by VerifyPeerCertificate.
+	if tlsConfig.InsecureSkipVerify && tlsConfig.VerifyPeerCertificate == nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "TLS certificate verification is disabled",
+			Detail:   "The server's certificate chain and host name are not verified, leaving the request open to man-in-the-middle attacks. Only use insecure_skip_verify for testing.",
+		})
+	}
+
+	// NTLM authenticates the connection, so it is not shared with other data
+	// sources.
+	var tr *http.Transport
+	if len(d.Get("ntlm_auth").([]interface{})) > 0 {
+		tr, err = newTransport(d, tlsConfig)
+	} else {
+		tr, err = config.sharedTransport(transportKey(d), func() (*http.Transport, error) {
+			return newTransport(d, tlsConfig)
+		})
+	}
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	client := &http.Client{
+		Transport: config.transport(tr),
+		Timeout:   time.Duration(requestTimeout) * time.Millisecond,
+	}
+
+	if !d.Get("follow_redirects").(bool) {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	requestURLs := append([]string(nil), rawURLs...)
+	if pathSegments := d.Get("path_segments").([]interface{}); len(pathSegments) > 0 {
+		for i := range requestURLs {
+			requestURLs[i], err = addPathSegments(requestURLs[i], pathSegments)
+			if err != nil {
+				return append(diags, diag.Errorf("Error adding path segments: %s", err)...)
+			}
+		}
+	}
+	if queryParameters := d.Get("query_parameters").(map[string]interface{}); len(queryParameters) > 0 {
+		for i := range requestURLs {
+			requestURLs[i], err = addQueryParameters(requestURLs[i], queryParameters)
+			if err != nil {
+				return append(diags, diag.Errorf("Error adding query parameters: %s", err)...)
+			}
+		}
+	}
+
+	// The request is built for the first URL and sent to the others only if
+	// it fails.
+	var failoverURLs []*url.URL
+	for _, rawURL := range requestURLs[1:] {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return append(diags, diag.Errorf("Error creating request: %s", err)...)
+		}
+		failoverURLs = append(failoverURLs, u)
+	}
+
+	body := []byte(d.Get("request_body").(string))
+
+	// request_body_file is streamed from disk, unless it is needed up front to
+	// sign the request.
+	var bodyFileSize int64
+	var openBodyFile func() (io.ReadCloser, error)
+	if bodyFile := d.Get("request_body_file").(string); bodyFile != "" {
+		if len(d.Get("hmac_signature").([]interface{})) > 0 || len(d.Get("aws_sigv4").([]interface{})) > 0 {
+			body, err = ioutil.ReadFile(bodyFile)
+		} else {
+			bodyFileSize, openBodyFile, err = fileBody(bodyFile)
+		}
+		if err != nil {
+			return append(diags, diag.Errorf("Error reading request_body_file: %s", err)...)
+		}
+	}
+
+	if bodyBase64 := d.Get("request_body_base64").(string); bodyBase64 != "" {
+		body, err = base64.StdEncoding.DecodeString(bodyBase64)
+		if err != nil {
+			return append(diags, diag.Errorf("Error decoding request_body_base64: %s", err)...)
+		}
+	}
+
+	bodyJSON := d.Get("request_body_json").(string)
+	if bodyJSON != "" {
+		body = []byte(bodyJSON)
+	}
+
+	if bodyTemplate := d.Get("body_template").(string); bodyTemplate != "" {
+		body, err = renderBodyTemplate(bodyTemplate, d.Get("body_vars").(map[string]interface{}))
+		if err != nil {
+			return append(diags, diag.Errorf("Error rendering body_template: %s", err)...)
+		}
+	}
+
+	// Many servers ignore or reject a body on a GET request, so it is only
+	// sent when asked for.
+	if method == http.MethodGet && (len(body) > 0 || bodyFileSize > 0) && !d.Get("allow_body_on_get").(bool) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Request body is not sent with a GET request",
+			Detail:   "Set allow_body_on_get to send the request body with a GET request, or set request_method to another method.",
+		})
+		body = nil
+		openBodyFile = nil
+	}
+
+	graphQLBody, err := expandGraphQLBody(d.Get("graphql").([]interface{}))
+	if err != nil {
+		return append(diags, diag.Errorf("Error building graphql request: %s", err)...)
+	}
+	if graphQLBody != nil {
+		method = http.MethodPost
+		body = graphQLBody
+	}
+
+	var multipartBody *multipartBody
+	if fields := expandMultipartFields(d.Get("multipart").([]interface{})); len(fields) > 0 {
+		multipartBody, err = newMultipartBody(fields)
+		if err != nil {
+			return append(diags, diag.Errorf("Error building multipart body: %s", err)...)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURLs[0], bytes.NewBuffer(body))
+	if err != nil {
+		return append(diags, diag.Errorf("Error creating request: %s", err)...)
+	}
+
+	// The jar carries cookies set by redirect responses on to the next
+	// request.
+	client.Jar, err = newCookieJar(append([]*url.URL{req.URL}, failoverURLs...), d.Get("cookies").(map[string]interface{}))
+	if err != nil {
+		return append(diags, diag.Errorf("Error creating cookie jar: %s", err)...)
+	}
+
+	req.Header.Set("User-Agent", defaultUserAgent())
+
+	for name, value := range config.defaultHeaders {
+		req.Header.Set(name, value)
+	}
+
+	// referer and origin take precedence over the provider's default_headers,
+	// but not over request_headers.
+	if referer := d.Get("referer").(string); referer != "" {
+		req.Header.Set("Referer", referer)
+	}
+
+	if origin := d.Get("origin").(string); origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+
+	for name, value := range headers {
+		req.Header.Set(name, value.(string))
+	}
+
+	for _, v := range d.Get("request_headers_list").([]interface{}) {
+		header := v.(map[string]interface{})
+		for _, value := range header["values"].([]interface{}) {
+			req.Header.Add(header["name"].(string), value.(string))
+		}
+	}
+
+	if userAgent := d.Get("user_agent").(string); userAgent != "" {
+		if d.Get("user_agent_append").(bool) {
+			userAgent += " " + defaultUserAgent()
+		}
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	if accept := d.Get("accept").(string); accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	// The transport only decompresses responses to requests it added
+	// Accept-Encoding to itself, so the body is left as received.
+	if acceptEncoding := d.Get("accept_encoding").(string); acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	if sse != nil && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+
+	if ifModifiedSince != "" {
+		t, err := parseHTTPTime(ifModifiedSince)
+		if err != nil {
+			return append(diags, diag.Errorf("Error parsing if_modified_since: %s", err)...)
+		}
+		req.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+	}
+
+	// Retries, redirects and further pages are sent with the same ID, as they
+	// belong to the one request made by the data source.
+	requestID := ""
+	if name := d.Get("request_id_header").(string); name != "" {
+		requestID = req.Header.Get(name)
+		if requestID == "" {
+			requestID, err = newRequestID()
+			if err != nil {
+				return append(diags, diag.Errorf("Error generating request ID: %s", err)...)
+			}
+			req.Header.Set(name, requestID)
+		}
+	}
+
+	// The Host entry of the header map is ignored when sending a request.
+	if hostOverride := d.Get("host_override").(string); hostOverride != "" {
+		req.Host = hostOverride
+	}
+
+	if multipartBody != nil {
+		req.Header.Set("Content-Type", multipartBody.contentType())
+	}
+
+	if graphQLBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Unlike graphql, a Content-Type set in the headers takes precedence.
+	if bodyJSON != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// multipart and graphql, whose bodies need their own Content-Type, conflict
+	// with request_content_type.
+	if contentType := d.Get("request_content_type").(string); contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if err := applyBasicAuth(req, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyBearerToken(req, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyOAuth2ClientCredentials(config, client, req, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyGCPIDToken(ctx, req, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyJWT(req, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyNTLMAuth(client, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyDigestAuth(client, d); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	destroyKerberosClient, err := applyKerberosAuth(client, d)
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	defer destroyKerberosClient()
+
+	if err := applyHMACSignature(req, d, body); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := applyAWSSigV4(req, d, body); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if multipartBody != nil {
+		// The body is streamed, so it is only opened once nothing else can
+		// fail before the request is sent.
+		req.Body, err = multipartBody.open()
+		if err != nil {
+			return append(diags, diag.Errorf("Error building multipart body: %s", err)...)
+		}
+		req.GetBody = multipartBody.open
+	}
+
+	if openBodyFile != nil {
+		req.Body, err = openBodyFile()
+		if err != nil {
+			return append(diags, diag.Errorf("Error reading request_body_file: %s", err)...)
+		}
+		req.GetBody = openBodyFile
+		req.ContentLength = bodyFileSize
+	}
+
+	// The transport only waits for the server before sending a body when it
+	// has an ExpectContinueTimeout.
+	if d.Get("expect_continue_timeout_ms").(int) > 0 && req.Body != nil && req.Body != http.NoBody {
+		req.Header.Set("Expect", "100-continue")
+	}
+
+	debug := d.Get("debug").(bool)
+	var debugRedactHeaders []string
+	for _, v := range d.Get("debug_redact_headers").([]interface{}) {
+		debugRedactHeaders = append(debugRedactHeaders, v.(string))
+	}
+
+	requestDump := ""
+	if debug {
+		requestDump, err = dumpRequest(req, debugRedactHeaders)
+		if err != nil {
+			return append(diags, diag.Errorf("Error dumping request: %s", err)...)
+		}
+	}
+	d.Set("request_dump", requestDump)
+
+	// Cookies are added from the jar as the request is sent.
+	sentHeader := req.Header.Clone()
+	for _, cookie := range client.Jar.Cookies(req.URL) {
+		sentHeader.Add("Cookie", cookie.String())
+	}
+	if err = d.Set("sent_request_headers", flattenRedactedHeaders(sentHeader, debugRedactHeaders)); err != nil {
+		return append(diags, diag.Errorf("Error setting sent request headers: %s", err)...)
+	}
+
+	if d.Get("validate_only").(bool) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+
+		// The provider's allowed_hosts is otherwise only checked as requests
+		// are sent.
+		if config.allowlist != nil {
+			for _, u := range append([]*url.URL{req.URL}, failoverURLs...) {
+				if host := u.Hostname(); !config.allowlist.allows(host) {
+					return append(diags, diag.Diagnostic{
+						Severity: diag.Error,
+						Summary:  fmt.Sprintf("Host %s is not allowed", host),
+						Detail:   fmt.Sprintf("The request would not be sent: %s. Add the host to allowed_hosts in the provider configuration to allow it.", &hostNotAllowedError{host: host}),
+					})
+				}
+			}
+		}
+
+		d.Set("validated", true)
+		d.SetId(rawURLs[0])
+		return diags
+	}
+	d.Set("validated", false)
+
+	// Requests or responses that are streamed, truncated or polled for are not
+	// cached, nor are NTLM, digest or Kerberos authenticated ones, as the
+	// credentials are not part of the key.
+	var cacheKey string
+	if config.cache != nil && multipartBody == nil && d.Get("request_body_file").(string) == "" && responseBodyFile == "" && wait == nil && sse == nil &&
+		d.Get("head_bytes").(int) == 0 && len(d.Get("ntlm_auth").([]interface{})) == 0 &&
+		len(d.Get("digest_auth").([]interface{})) == 0 && len(d.Get("kerberos_auth").([]interface{})) == 0 {
+		cacheKey = responseCacheKey(req, body, client.Jar.Cookies(req.URL), d)
+	}
+
+	// A signature covers the host, so the request is signed again for each
+	// URL it fails over to and each page it follows.
+	resign := func(r *http.Request) error {
+		return applyAWSSigV4(r, d, body)
+	}
+
+	start := time.Now()
+	var resp *http.Response
+	var attempts int
+	var resolvedURLIndex int
+	var cached *cachedResponse
+	var releaseCache func(*cachedResponse)
+	if cacheKey != "" {
+		cached, releaseCache, err = config.cache.acquire(ctx, cacheKey)
+		if err != nil {
+			return append(diags, diag.Errorf("Error waiting for cached response: %s", err)...)
+		}
+		if releaseCache != nil {
+			// Wakes other readers of the key if the response is not stored.
+			defer releaseCache(nil)
+		}
+	}
+	if cached != nil {
+		resp = cached.response()
+		resolvedURLIndex = cached.urlIndex
+	} else if wait != nil {
+		resp, attempts, err = doRequestUntil(ctx, client, req, retry, wait, int64(maxResponseBodyBytes))
+	} else {
+		resp, resolvedURLIndex, attempts, err = doRequestWithFailover(ctx, client, req, failoverURLs, retry, resign)
+	}
+	if err != nil {
+		var circuitErr *circuitOpenError
+		if errors.As(err, &circuitErr) {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Circuit breaker is open for %s%s", circuitErr.host, attemptsSuffix(attempts)),
+				Detail:   fmt.Sprintf("The request was not sent: %s.", circuitErr),
+			})
+		}
+
+		var hostErr *hostNotAllowedError
+		if errors.As(err, &hostErr) {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Host %s is not allowed", hostErr.host),
+				Detail:   fmt.Sprintf("The request was not sent: %s. Add the host to allowed_hosts in the provider configuration to allow it.", hostErr),
+			})
+		}
+
+		var privateIPErr *privateIPError
+		if errors.As(err, &privateIPErr) {
+			return append(diags, diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Connection to %s address %s is blocked", privateIPErr.kind, privateIPErr.ip),
+				Detail:   fmt.Sprintf("The request was not sent: %s. Add the address to allowed_private_cidrs to allow it.", privateIPErr),
+			})
+		}
+
+		msg := fmt.Sprintf("Error making request: %s", err)
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			msg = timeoutMessage(err, requestTimeout, d.Get("dial_timeout_ms").(int), d.Get("tls_handshake_timeout_ms").(int))
+		}
+		return append(diags, diag.Errorf("%s%s", msg, attemptsSuffix(attempts))...)
+	}
+
+	defer resp.Body.Close()
+
+	if releaseCache != nil && isResponseCacheable(resp) {
+		data, err := readResponseBody(resp.Body, int64(maxResponseBodyBytes))
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		cachedResp := newCachedResponse(resp, data)
+		cachedResp.urlIndex = resolvedURLIndex
+		releaseCache(cachedResp)
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+
+	responseDump := ""
+	if debug {
+		// A body streamed to response_body_file is not read into memory.
+		responseDump, err = dumpResponse(resp, responseBodyFile == "", debugRedactHeaders)
+		if err != nil {
+			return append(diags, diag.Errorf("Error dumping response: %s", err)...)
+		}
+	}
+	d.Set("response_dump", responseDump)
+
+	d.Set("status_code", resp.StatusCode)
+	d.Set("status", resp.Status)
+	d.Set("final_url", resp.Request.URL.String())
+	d.Set("resolved_url_index", resolvedURLIndex)
+	d.Set("from_cache", cached != nil)
+	d.Set("etag", resp.Header.Get("ETag"))
+	d.Set("request_id", requestID)
+
+	// These describe the connection of the final response, after redirects.
+	d.Set("used_tls", resp.TLS != nil)
+	d.Set("response_protocol", resp.Proto)
+	tlsCertNotAfter, tlsCertIssuer, tlsCertSubject := "", "", ""
+	if cert := peerCertificate(resp.TLS); cert != nil {
+		tlsCertNotAfter = cert.NotAfter.UTC().Format(time.RFC3339)
+		tlsCertIssuer = cert.Issuer.String()
+		tlsCertSubject = cert.Subject.String()
+	}
+	d.Set("tls_cert_not_after", tlsCertNotAfter)
+	d.Set("tls_cert_issuer", tlsCertIssuer)
+	d.Set("tls_cert_subject", tlsCertSubject)
+
+	// A 304 response to a conditional request has no body to check.
+	notModified := (ifNoneMatch != "" || ifModifiedSince != "") && resp.StatusCode == http.StatusNotModified
+
+	if !notModified && !isStatusCodeExpected(resp.StatusCode, expectedStatusCodes) {
+		d.Set("response_time_ms", time.Since(start).Milliseconds())
+		return append(diags, diag.Errorf("HTTP request error. Response code: %d%s", resp.StatusCode, attemptsSuffix(attempts))...)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	contentTypeMIME, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		contentTypeMIME = ""
+	}
+	d.Set("content_type", contentType)
+	d.Set("content_type_mime", contentTypeMIME)
+
+	// Binary content is only a concern when it is stored in the state.
+	if !notModified && responseBodyFile == "" && !d.Get("skip_content_type_warning").(bool) &&
+		(contentType == "" || isContentTypeText(contentType) == false) {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Content-Type is not recognized as a text type, got %q", contentType),
+			Detail:   "If the content is binary data, Terraform may not properly handle the contents of the response.",
+		})
+	}
+
+	if !notModified && !isContentTypeExpected(contentType, expectedContentType) {
+		return append(diags, diag.Errorf("Content-Type %q does not match expected_content_type %q", contentType, expectedContentType)...)
+	}
+
+	var bytes []byte
+	var bodyLength int64
+	var pagesFetched int
+	sseEvents := []interface{}{}
+	bodySHA256 := ""
+	// The length is -1 when the server does not send Content-Length, until the
+	// body has been read.
+	contentLength := resp.ContentLength
+	if responseBodyFile != "" {
+		// A 304 response has no body, so the file from the previous read is
+		// left as it is.
+		if !notModified {
+			var written int64
+			bodySHA256, written, err = writeResponseBodyFile(responseBodyFile, resp.Body, int64(maxResponseBodyBytes))
+			if err != nil {
+				return append(diags, diag.Errorf("Error writing response_body_file: %s", err)...)
+			}
+			if contentLength < 0 {
+				contentLength = written
+			}
+			bodyLength = written
+		}
+	} else {
+		headBytes := int64(d.Get("head_bytes").(int))
+		if sse != nil {
+			var events []sseEvent
+			var timedOut bool
+			events, bytes, timedOut, err = readSSEEvents(resp.Body, sse, int64(maxResponseBodyBytes))
+			if timedOut && sse.terminateOnEvent != "" {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("Event stream timed out before a %q event", sse.terminateOnEvent),
+					Detail:   fmt.Sprintf("%d events were read in %s. Increase timeout_ms in the sse block to wait longer.", len(events), sse.timeout),
+				})
+			}
+			sseEvents = flattenSSEEvents(events)
+		} else if headBytes > 0 {
+			// The rest of the body is discarded when resp.Body is closed.
+			bytes, err = ioutil.ReadAll(io.LimitReader(resp.Body, headBytes))
+		} else {
+			bytes, err = readResponseBody(resp.Body, int64(maxResponseBodyBytes))
+		}
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		if paginate != nil && !notModified {
+			pages, truncated, err := fetchPages(ctx, client, req, resp, bytes, paginate, retry, expectedStatusCodes, int64(maxResponseBodyBytes), resign)
+			if err != nil {
+				return append(diags, diag.FromErr(err)...)
+			}
+			if truncated {
+				diags = append(diags, diag.Diagnostic{
+					Severity: diag.Warning,
+					Summary:  fmt.Sprintf("Pagination stopped after %d pages", len(pages)),
+					Detail:   "The last page fetched links to a next page. Increase max_pages in the paginate block to fetch more pages.",
+				})
+			}
+			bytes, err = paginate.combine(pages)
+			if err != nil {
+				return append(diags, diag.Errorf("Error combining pages: %s", err)...)
+			}
+			pagesFetched = len(pages)
+			// The length is that of the combined body rather than the first
+			// page.
+			contentLength = int64(len(bytes))
+		}
+		if !notModified {
+			sum := sha256.Sum256(bytes)
+			bodySHA256 = hex.EncodeToString(sum[:])
+		}
+		// A body cut short by head_bytes leaves the length unknown.
+		if contentLength < 0 && (headBytes == 0 || int64(len(bytes)) < headBytes) {
+			contentLength = int64(len(bytes))
+		}
+		bodyLength = int64(len(bytes))
+	}
+	// Trailers are only received once the body has been read to the end. Those
+	// announced in the Trailer header but never sent have no values.
+	responseTrailers := make(map[string]string)
+	for k, v := range resp.Trailer {
+		if len(v) > 0 {
+			responseTrailers[k] = strings.Join(v, ", ")
+		}
+	}
+
+	d.Set("body_sha256", bodySHA256)
+	d.Set("content_length", contentLength)
+	d.Set("pages_fetched", pagesFetched)
+	if err = d.Set("sse_events", sseEvents); err != nil {
+		return append(diags, diag.Errorf("Error setting sse_events: %s", err)...)
+	}
+
+	d.Set("response_time_ms", time.Since(start).Milliseconds())
+
+	if !notModified && bodyLength == 0 && d.Get("fail_on_empty_body").(bool) {
+		return append(diags, diag.Errorf("Response body is empty")...)
+	}
+
+	// Headers named in redact_response_headers are kept out of the state.
+	stateHeader := resp.Header.Clone()
+	for _, v := range d.Get("redact_response_headers").([]interface{}) {
+		stateHeader.Del(v.(string))
+	}
+
+	responseHeaders := flattenResponseHeaders(stateHeader)
+
+	charset, text, err := decodeBody(contentType, bytes)
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Response body could not be converted to UTF-8",
+			Detail:   fmt.Sprintf("body is left as received: %s", err),
+		})
+	}
+	d.Set("charset", charset)
+
+	d.Set("body", string(text))
+	d.Set("body_base64", base64.StdEncoding.EncodeToString(bytes))
+
+	// The body is parsed as text from here on.
+	bytes = text
+
+	if graphQLBody != nil {
+		if errs := graphQLErrors(bytes); errs.HasError() {
+			return append(diags, errs...)
+		}
+	}
+
+	responseBodyJSON := ""
+	if isContentTypeJSON(contentType) {
+		responseBodyJSON, err = normalizeJSON(bytes)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Response body could not be parsed as JSON",
+				Detail:   fmt.Sprintf("The Content-Type %q indicates JSON, but the body could not be parsed: %s", contentType, err),
+			})
+		}
+	}
+	d.Set("response_body_json", responseBodyJSON)
+
+	// The schema is only checked against a body that was parsed as JSON.
+	if jsonSchema != nil && responseBodyJSON != "" {
+		if errs := jsonSchemaViolations(jsonSchema, bytes); errs.HasError() {
+			return append(diags, errs...)
+		}
+	}
+
+	responseBodyXML := ""
+	if isContentTypeXML(contentType) {
+		responseBodyXML, err = xmlToJSON(bytes)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Response body could not be parsed as XML",
+				Detail:   fmt.Sprintf("The Content-Type %q indicates XML, but the body could not be parsed: %s", contentType, err),
+			})
+		}
+	}
+	d.Set("response_body_xml", responseBodyXML)
+
+	bodyURLDecoded, err := url.QueryUnescape(string(bytes))
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Response body could not be URL decoded",
+			Detail:   fmt.Sprintf("body_url_decoded is left empty: %s", err),
+		})
+		bodyURLDecoded = ""
+	}
+	d.Set("body_url_decoded", bodyURLDecoded)
+
+	jsonValues := map[string]string{}
+	if jsonPaths := d.Get("json_paths").(map[string]interface{}); len(jsonPaths) > 0 && !notModified {
+		jsonValues, err = extractJSONValues(bytes, jsonPaths, d.Get("json_paths_strict").(bool))
+		if err != nil {
+			return append(diags, diag.Errorf("Error evaluating json_paths: %s", err)...)
+		}
+	}
+	if err = d.Set("json_values", jsonValues); err != nil {
+		return append(diags, diag.Errorf("Error setting json_values: %s", err)...)
+	}
+
+	bodyMatch, bodyCaptures := "", []string{}
+	if pattern := d.Get("body_regex").(string); pattern != "" && !notModified {
+		bodyMatch, bodyCaptures, err = matchBodyRegex(bytes, pattern, d.Get("body_regex_strict").(bool))
+		if err != nil {
+			return append(diags, diag.Errorf("Error evaluating body_regex: %s", err)...)
+		}
+	}
+	d.Set("body_match", bodyMatch)
+	if err = d.Set("body_captures", bodyCaptures); err != nil {
+		return append(diags, diag.Errorf("Error setting body_captures: %s", err)...)
+	}
+	if err = d.Set("response_headers", responseHeaders); err != nil {
+		return append(diags, diag.Errorf("Error setting HTTP response headers: %s", err)...)
+	}
+	if err = d.Set("response_trailers", responseTrailers); err != nil {
+		return append(diags, diag.Errorf("Error setting HTTP response trailers: %s", err)...)
+	}
+
+	responseHeadersLower := make(map[string]string, len(responseHeaders))
+	for k, v := range responseHeaders {
+		responseHeadersLower[strings.ToLower(k)] = v
+	}
+	if err = d.Set("response_headers_lower", responseHeadersLower); err != nil {
+		return append(diags, diag.Errorf("Error setting HTTP response headers: %s", err)...)
+	}
+	if err = d.Set("response_headers_list", flattenResponseHeadersList(stateHeader)); err != nil {
+		return append(diags, diag.Errorf("Error setting HTTP response headers: %s", err)...)
+	}
+
+	// set ID as something more stable than time
+	d.SetId(rawURLs[0])
+
+	return diags
+}
+
+// parseHTTPTime parses a time given in RFC 3339 format or in one of the
+// formats allowed in HTTP headers, such as RFC 1123.
+func parseHTTPTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not in RFC 1123 or RFC 3339 format", v)
+	}
+
+	return t, nil
+}
+
+// newRequestID returns a random version 4 UUID as described in RFC 4122.
+func newRequestID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// readResponseBody reads the whole response body. When limit is positive, an
+// error is returned if the body is larger than limit bytes.
+func readResponseBody(body io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return ioutil.ReadAll(body)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("Response body exceeds the max_response_body_bytes limit of %d bytes", limit)
+	}
+
+	return data, nil
+}
+
+// writeResponseBodyFile streams the response body to path, creating missing
+// parent directories, and returns the hex encoded SHA-256 checksum of the
+// contents and its length. When limit is positive, an error is returned and
+// the file removed if the body is larger than limit bytes.
+func writeResponseBodyFile(path string, body io.Reader, limit int64) (string, int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && limit > 0 && n > limit {
+		err = fmt.Errorf("Response body exceeds the max_response_body_bytes limit of %d bytes", limit)
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// fileBody returns the size of the file at path and a function opening it, so
+// that it can be streamed as a request body and opened again for each retry.
+func fileBody(path string) (int64, func() (io.ReadCloser, error), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, nil, err
+	}
+	if info.IsDir() {
+		return 0, nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	// A body with a ContentLength of 0 is taken to be of unknown length and
+	// sent chunked, so an empty file is sent like any other empty body.
+	if info.Size() == 0 {
+		return 0, nil, nil
+	}
+
+	open := func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+
+	return info.Size(), open, nil
+}
+
+// newCookieJar creates a cookie jar holding the given cookies for each of urls.
+func newCookieJar(urls []*url.URL, cookies map[string]interface{}) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var seed []*http.Cookie
+	for name, value := range cookies {
+		seed = append(seed, &http.Cookie{Name: name, Value: value.(string)})
+	}
+	for _, u := range urls {
+		jar.SetCookies(u, seed)
+	}
+
+	return jar, nil
+}
+
+// flattenResponseHeaders converts response headers to a map of strings,
+// joining repeated headers into a single value.
+func flattenResponseHeaders(header http.Header) map[string]string {
+	responseHeaders := make(map[string]string)
+	for k, v := range header {
+		// Concatenate according to RFC2616
+		// cf. https://www.w3.org/Protocols/rfc2616/rfc2616-sec4.html#sec4.2
+		responseHeaders[k] = strings.Join(v, ", ")
+	}
+
+	return responseHeaders
+}
+
+// flattenResponseHeadersList converts response headers to a list of name and
+// values blocks sorted by name. Unlike flattenResponseHeaders it keeps each
+// value of a repeated header, as a map of lists cannot be stored in state.
+func flattenResponseHeadersList(header http.Header) []interface{} {
+	names := make([]string, 0, len(header))
+	for k := range header {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	responseHeaders := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		responseHeaders = append(responseHeaders, map[string]interface{}{
+			"name":   name,
+			"values": header[name],
+		})
+	}
+
+	return responseHeaders
+}
+
+// addQueryParameters URL-encodes the given parameters and appends them to the
+// query string of rawURL. The query already present is kept as written, as
+// servers may treat a reordered or re-encoded query differently.
+func addQueryParameters(rawURL string, parameters map[string]interface{}) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	for name, value := range parameters {
+		query.Add(name, value.(string))
+	}
+
+	if u.RawQuery == "" || strings.HasSuffix(u.RawQuery, "&") {
+		u.RawQuery += query.Encode()
+	} else {
+		u.RawQuery += "&" + query.Encode()
+	}
+
+	return u.String(), nil
+}
+
+// addPathSegments appends segments to the path of rawURL. Each segment is
+// escaped, so that characters such as "/" and "?" are sent as part of it.
+func addPathSegments(rawURL string, segments []interface{}) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	escapedPath := strings.TrimSuffix(u.EscapedPath(), "/")
+	for _, segment := range segments {
+		escapedPath += "/" + url.PathEscape(segment.(string))
+	}
+
+	// Path holds the unescaped path, in which an escaped "/" cannot be told
+	// apart, so RawPath keeps the escaping.
+	if u.Path, err = url.PathUnescape(escapedPath); err != nil {
+		return "", err
+	}
+	u.RawPath = escapedPath
+
+	return u.String(), nil
+}
+
+// isStatusCodeExpected reports whether the response status code should be
+// treated as successful. With no expected codes configured, any 2xx is accepted.
+func isStatusCodeExpected(statusCode int, expectedStatusCodes []interface{}) bool {
+	if len(expectedStatusCodes) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+
+	for _, code := range expectedStatusCodes {
+		if code.(int) == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isContentTypeExpected reports whether the content type equals or starts with
+// the expected value, ignoring case. An empty expected value matches anything.
+func isContentTypeExpected(contentType, expected string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), strings.ToLower(expected))
+}
+
+// isContentTypeJSON reports whether the content type is application/json or
+// a structured syntax suffix of it, such as application/problem+json.
+func isContentTypeJSON(contentType string) bool {
+	parsedType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return parsedType == "application/json" || strings.HasSuffix(parsedType, "+json")
+}
+
+// normalizeJSON parses the given JSON document and re-encodes it in a compact
+// form with object keys sorted. Numbers are preserved exactly as received.
+func normalizeJSON(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return "", err
+	}
+	if decoder.More() {
+		return "", fmt.Errorf("unexpected data after top-level value")
+	}
+
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(normalized), nil
 }
 
 // This is to prevent potential issues w/ binary files
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func headDataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: headDataSourceRead,
+
+		Schema: map[string]*schema.Schema{
+			"url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The URL a HEAD request is sent to.",
+			},
+
+			"request_headers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "A map of request headers sent with the request.",
+			},
+
+			"request_timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "The request timeout in milliseconds. Defaults to the provider's timeout_ms, or no timeout.",
+			},
+
+			"status_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The HTTP response status code.",
+			},
+
+			"content_length": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The Content-Length response header, or -1 if the server did not send one.",
+			},
+
+			"content_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The Content-Type response header as sent by the server.",
+			},
+
+			"content_type_mime": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The media type of the Content-Type response header in lower case, without parameters such as charset.",
+			},
+
+			"last_modified": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The Last-Modified response header in RFC 3339 format. Empty if the server did not send a valid one.",
+			},
+
+			"etag": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ETag response header.",
+			},
+
+			"final_url": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The URL the response was received from, after redirects.",
+			},
+
+			"response_headers": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The response headers. Duplicate headers are concatenated with a comma.",
+			},
+		},
+	}
+}
+
+// headDataSourceRead sends a HEAD request, so only the metadata of the
+// resource is transferred.
+func headDataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	config := meta.(*providerConfig)
+	url := d.Get("url").(string)
+
+	requestTimeout := d.Get("request_timeout_ms").(int)
+	if requestTimeout == 0 {
+		requestTimeout = config.timeoutMs
+	}
+
+	tr, err := config.defaultTransport()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	client := &http.Client{
+		Transport: config.transport(tr),
+		Timeout:   time.Duration(requestTimeout) * time.Millisecond,
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return diag.Errorf("Error creating request: %s", err)
+	}
+
+	req.Header.Set("User-Agent", defaultUserAgent())
+
+	for name, value := range config.defaultHeaders {
+		req.Header.Set(name, value)
+	}
+
+	for name, value := range d.Get("request_headers").(map[string]interface{}) {
+		req.Header.Set(name, value.(string))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return diag.Errorf("%s", timeoutMessage(err, requestTimeout, 0, 0))
+		}
+		return diag.Errorf("Error making request: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if !isStatusCodeExpected(resp.StatusCode, nil) {
+		return diag.Errorf("HTTP request error. Response code: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	contentTypeMIME, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		contentTypeMIME = ""
+	}
+
+	lastModified := ""
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		lastModified = t.UTC().Format(time.RFC3339)
+	}
+
+	d.Set("status_code", resp.StatusCode)
+	d.Set("content_length", resp.ContentLength)
+	d.Set("content_type", contentType)
+	d.Set("content_type_mime", contentTypeMIME)
+	d.Set("last_modified", lastModified)
+	d.Set("etag", resp.Header.Get("ETag"))
+	d.Set("final_url", resp.Request.URL.String())
+	if err := d.Set("response_headers", flattenResponseHeaders(resp.Header)); err != nil {
+		return diag.Errorf("Error setting HTTP response headers: %s", err)
+	}
+
+	d.SetId(url)
+
+	return nil
+}
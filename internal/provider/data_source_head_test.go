@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// setUpMockHeadServer starts a server that only answers HEAD requests, so a
+// test fails if the data source asks for the body.
+func setUpMockHeadServer() *TestHttpMock {
+	Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/file.tar.gz":
+			w.Header().Set("Content-Length", "1048576")
+			w.Header().Set("Content-Type", "application/gzip")
+			w.Header().Set("Last-Modified", time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+			w.Header().Set("ETag", `"abc123"`)
+			w.Header().Set("X-Single", "foobar")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return &TestHttpMock{
+		server: Server,
+	}
+}
+
+const testDataSourceConfig_head = `
+data "http_head" "http_test" {
+  url = "%s/%s"
+}
+
+output "status_code" {
+  value = data.http_head.http_test.status_code
+}
+
+output "content_length" {
+  value = data.http_head.http_test.content_length
+}
+
+output "content_type" {
+  value = data.http_head.http_test.content_type
+}
+
+output "last_modified" {
+  value = data.http_head.http_test.last_modified
+}
+
+output "etag" {
+  value = data.http_head.http_test.etag
+}
+
+output "response_headers" {
+  value = data.http_head.http_test.response_headers
+}
+`
+
+func TestDataSource_head(t *testing.T) {
+	testHttpMock := setUpMockHeadServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_head, testHttpMock.server.URL, "file.tar.gz"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					expected := map[string]string{
+						"status_code":    "200",
+						"content_length": "1048576",
+						"content_type":   "application/gzip",
+						"last_modified":  "2020-01-01T00:00:00Z",
+						"etag":           `"abc123"`,
+					}
+					for name, want := range expected {
+						if outputs[name].Value != want {
+							return fmt.Errorf(
+								`'%s' output is %s; want '%s'`,
+								name,
+								outputs[name].Value,
+								want,
+							)
+						}
+					}
+
+					response_headers := outputs["response_headers"].Value.(map[string]interface{})
+
+					if response_headers["X-Single"].(string) != "foobar" {
+						return fmt.Errorf(
+							`'X-Single' response header is %s; want 'foobar'`,
+							response_headers["X-Single"].(string),
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_head404(t *testing.T) {
+	testHttpMock := setUpMockHeadServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_head, testHttpMock.server.URL, "missing.tar.gz"),
+				ExpectError: regexp.MustCompile("HTTP request error. Response code: 404"),
+			},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+//go:build linux
+// +build linux
+
+package provider
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// listenWithFullBacklog returns the address of a loopback listener that never
+// accepts connections. Its accept queue is filled, so Linux drops further
+// connection attempts and dialling it blocks until the dial times out. The
+// returned function closes the listener and the connections filling it.
+func listenWithFullBacklog(t *testing.T) (string, func()) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conns []net.Conn
+	closeAll := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		syscall.Close(fd)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Addr: [4]byte{127, 0, 0, 1}}); err != nil {
+		closeAll()
+		t.Fatal(err)
+	}
+	// A backlog of 0 still queues a connection, so a few are made below.
+	if err := syscall.Listen(fd, 0); err != nil {
+		closeAll()
+		t.Fatal(err)
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		closeAll()
+		t.Fatal(err)
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", sa.(*syscall.SockaddrInet4).Port)
+
+	for i := 0; i < 8; i++ {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return addr, closeAll
+			}
+			closeAll()
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+
+	closeAll()
+	t.Skip("could not fill the accept queue of the listener")
+	return "", nil
+}
+
+const testDataSourceConfig_dialTimeout = `
+data "http" "http_test" {
+  url = "http://%s/meta_200.txt"
+
+  dial_timeout_ms    = 100
+  request_timeout_ms = 30000
+}
+`
+
+func TestDataSource_dialTimeout(t *testing.T) {
+	addr, closeListener := listenWithFullBacklog(t)
+
+	defer closeListener()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_dialTimeout, addr),
+				ExpectError: regexp.MustCompile("Connection timed out after 100ms"),
+			},
+		},
+	})
+}
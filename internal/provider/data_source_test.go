@@ -1,15 +1,49 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"golang.org/x/oauth2"
 )
 
 type TestHttpMock struct {
@@ -28,7 +62,64 @@ output "body" {
 output "response_headers" {
   value = data.http.http_test.response_headers
 }
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+`
+const testDataSourceConfig_responseHeadersLower = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+}
+
+output "content_type" {
+  value = data.http.http_test.response_headers["Content-Type"]
+}
+
+output "content_type_lower" {
+  value = data.http.http_test.response_headers_lower["content-type"]
+}
 `
+
+func TestDataSource_responseHeadersLower(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_responseHeadersLower, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["content_type"].Value != "text/plain" {
+						return fmt.Errorf(
+							`'content_type' output is %s; want 'text/plain'`,
+							outputs["content_type"].Value,
+						)
+					}
+
+					if outputs["content_type_lower"].Value != "text/plain" {
+						return fmt.Errorf(
+							`'content_type_lower' output is %s; want 'text/plain'`,
+							outputs["content_type_lower"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
 const testDataSourceConfig_post = `
 data "http" "http_test" {
   url = "%s/meta_%d.txt"
@@ -70,6 +161,13 @@ func TestDataSource_http200(t *testing.T) {
 						)
 					}
 
+					if outputs["status_code"].Value != "200" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '200'`,
+							outputs["status_code"].Value,
+						)
+					}
+
 					response_headers := outputs["response_headers"].Value.(map[string]interface{})
 
 					if response_headers["X-Single"].(string) != "foobar" {
@@ -141,6 +239,143 @@ func TestDataSource_post_http200(t *testing.T) {
 	})
 }
 
+const testDataSourceConfig_responseHeadersList = `
+data "http" "http_test" {
+  url = "%s/set-cookie/meta_%d.txt"
+}
+
+output "response_headers_list" {
+  value = { for h in data.http.http_test.response_headers_list : h.name => h.values }
+}
+`
+
+func TestDataSource_responseHeadersList(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_responseHeadersList, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					response_headers_list := outputs["response_headers_list"].Value.(map[string]interface{})
+
+					setCookie := response_headers_list["Set-Cookie"].([]interface{})
+					expected := []string{"session=abc; Path=/; HttpOnly", "theme=dark, light; Path=/"}
+					if len(setCookie) != len(expected) {
+						return fmt.Errorf(
+							`'Set-Cookie' response header has %d values; want %d`,
+							len(setCookie),
+							len(expected),
+						)
+					}
+					for i, want := range expected {
+						if setCookie[i].(string) != want {
+							return fmt.Errorf(
+								`'Set-Cookie' response header value %d is %s; want '%s'`,
+								i,
+								setCookie[i].(string),
+								want,
+							)
+						}
+					}
+
+					xSingle := response_headers_list["X-Single"].([]interface{})
+					if len(xSingle) != 1 || xSingle[0].(string) != "foobar" {
+						return fmt.Errorf(
+							`'X-Single' response header is %v; want ['foobar']`,
+							xSingle,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_redactResponseHeaders = `
+data "http" "http_test" {
+  url = "%s/set-cookie/meta_%d.txt"
+
+  redact_response_headers = ["set-cookie"]
+}
+
+output "response_headers" {
+  value = data.http.http_test.response_headers
+}
+
+output "response_headers_list" {
+  value = [for h in data.http.http_test.response_headers_list : h.name]
+}
+`
+
+func TestDataSource_redactResponseHeaders(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_redactResponseHeaders, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					response_headers := outputs["response_headers"].Value.(map[string]interface{})
+
+					if _, ok := response_headers["Set-Cookie"]; ok {
+						return fmt.Errorf(`'Set-Cookie' response header is present; want it redacted`)
+					}
+
+					if response_headers["X-Single"].(string) != "foobar" {
+						return fmt.Errorf(
+							`'X-Single' response header is %s; want 'foobar'`,
+							response_headers["X-Single"].(string),
+						)
+					}
+
+					for _, name := range outputs["response_headers_list"].Value.([]interface{}) {
+						if name.(string) == "Set-Cookie" {
+							return fmt.Errorf(`'Set-Cookie' is present in response_headers_list; want it redacted`)
+						}
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestFlattenResponseHeadersList(t *testing.T) {
+	header := http.Header{}
+	header.Add("X-Single", "foobar")
+	header.Add("Set-Cookie", "a=1")
+	header.Add("Set-Cookie", "b=2, 3")
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name":   "Set-Cookie",
+			"values": []string{"a=1", "b=2, 3"},
+		},
+		map[string]interface{}{
+			"name":   "X-Single",
+			"values": []string{"foobar"},
+		},
+	}
+
+	if got := flattenResponseHeadersList(header); !reflect.DeepEqual(got, expected) {
+		t.Errorf("flattenResponseHeadersList() = %v; want %v", got, expected)
+	}
+}
+
 func TestDataSource_http404(t *testing.T) {
 	testHttpMock := setUpMockHttpServer()
 
@@ -157,45 +392,51 @@ func TestDataSource_http404(t *testing.T) {
 	})
 }
 
-const testDataSourceConfig_withHeaders = `
+const testDataSourceConfig_urls = `
 data "http" "http_test" {
-  url = "%s/restricted/meta_%d.txt"
-
-  request_headers = {
-    "Authorization" = "Zm9vOmJhcg=="
-  }
+  urls = ["%s/meta_%d.txt", "%s/meta_%d.txt"]
 }
 
 output "body" {
   value = data.http.http_test.body
 }
+
+output "resolved_url_index" {
+  value = data.http.http_test.resolved_url_index
+}
 `
 
-func TestDataSource_withHeaders200(t *testing.T) {
+func TestDataSource_urls(t *testing.T) {
 	testHttpMock := setUpMockHttpServer()
 
 	defer testHttpMock.server.Close()
 
+	// The first URL is down.
+	downHttpMock := setUpMockHttpServer()
+	downHttpMock.server.Close()
+
 	resource.UnitTest(t, resource.TestCase{
 		Providers: testProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(testDataSourceConfig_withHeaders, testHttpMock.server.URL, 200),
+				Config: fmt.Sprintf(testDataSourceConfig_urls, downHttpMock.server.URL, 200, testHttpMock.server.URL, 200),
 				Check: func(s *terraform.State) error {
-					_, ok := s.RootModule().Resources["data.http.http_test"]
-					if !ok {
-						return fmt.Errorf("missing data resource")
-					}
-
 					outputs := s.RootModule().Outputs
 
-					if outputs["body"].Value != "1.0.0" {
+					if outputs["body"].Value != "1.0.0,GET" {
 						return fmt.Errorf(
-							`'body' output is %s; want '1.0.0'`,
+							`'body' output is %s; want '1.0.0,GET'`,
 							outputs["body"].Value,
 						)
 					}
 
+					if outputs["resolved_url_index"].Value != "1" {
+						return fmt.Errorf(
+							`'resolved_url_index' output is %s; want '1'`,
+							outputs["resolved_url_index"].Value,
+						)
+					}
+
 					return nil
 				},
 			},
@@ -203,17 +444,42 @@ func TestDataSource_withHeaders200(t *testing.T) {
 	})
 }
 
-const testDataSourceConfig_utf8 = `
+const testDataSourceConfig_urlAndURLs = `
 data "http" "http_test" {
-  url = "%s/utf-8/meta_%d.txt"
+  url  = "%s/meta_%d.txt"
+  urls = ["%s/meta_%d.txt"]
+}
+`
+
+func TestDataSource_urlAndURLs(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_urlAndURLs, testHttpMock.server.URL, 200, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("only one of `url,urls` can be specified"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_requestBodyBase64 = `
+data "http" "http_test" {
+  url                 = "%s/binary/meta_%d.txt"
+  request_method      = "POST"
+  request_body_base64 = "%s"
 }
 
 output "body" {
-  value = "${data.http.http_test.body}"
+  value = data.http.http_test.body
 }
 `
 
-func TestDataSource_utf8(t *testing.T) {
+func TestDataSource_requestBodyBase64(t *testing.T) {
 	testHttpMock := setUpMockHttpServer()
 
 	defer testHttpMock.server.Close()
@@ -222,18 +488,13 @@ func TestDataSource_utf8(t *testing.T) {
 		Providers: testProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(testDataSourceConfig_utf8, testHttpMock.server.URL, 200),
+				Config: fmt.Sprintf(testDataSourceConfig_requestBodyBase64, testHttpMock.server.URL, 200, base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10, 0x80})),
 				Check: func(s *terraform.State) error {
-					_, ok := s.RootModule().Resources["data.http.http_test"]
-					if !ok {
-						return fmt.Errorf("missing data resource")
-					}
-
 					outputs := s.RootModule().Outputs
 
-					if outputs["body"].Value != "1.0.0" {
+					if outputs["body"].Value != "00ff1080" {
 						return fmt.Errorf(
-							`'body' output is %s; want '1.0.0'`,
+							`'body' output is %s; want '00ff1080'`,
 							outputs["body"].Value,
 						)
 					}
@@ -245,78 +506,6000 @@ func TestDataSource_utf8(t *testing.T) {
 	})
 }
 
-const testDataSourceConfig_utf16 = `
+const testDataSourceConfig_urlInvalid = `
 data "http" "http_test" {
-  url = "%s/utf-16/meta_%d.txt"
+  url = "%s"
+}
+`
+
+func TestDataSource_urlInvalid(t *testing.T) {
+	cases := map[string]struct {
+		URL         string
+		ExpectError *regexp.Regexp
+	}{
+		"relative": {
+			URL:         "/meta_200.txt",
+			ExpectError: regexp.MustCompile(`expected url to be an absolute URL`),
+		},
+		"unsupported scheme": {
+			URL:         "gopher://example.com/meta_200.txt",
+			ExpectError: regexp.MustCompile(`expected url to have one of the schemes \[http, https\], got "gopher"`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      fmt.Sprintf(testDataSourceConfig_urlInvalid, tc.URL),
+						ExpectError: tc.ExpectError,
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_paginate = `
+data "http" "http_test" {
+  url = "%s/paginate/meta_%d.txt"
+
+  paginate {
+    max_pages = %d
+    mode      = "%s"
+  }
 }
 
 output "body" {
-  value = "${data.http.http_test.body}"
+  value = data.http.http_test.body
+}
+
+output "pages_fetched" {
+  value = data.http.http_test.pages_fetched
 }
 `
 
-func TestDataSource_utf16(t *testing.T) {
+func TestDataSource_paginate(t *testing.T) {
+	cases := map[string]struct {
+		MaxPages      int
+		Mode          string
+		ExpectedBody  string
+		ExpectedPages string
+	}{
+		"concat json arrays": {
+			MaxPages:      10,
+			Mode:          "concat-json-arrays",
+			ExpectedBody:  `[1,2,3,{"id":4}]`,
+			ExpectedPages: "3",
+		},
+		"collect bodies": {
+			MaxPages:      10,
+			Mode:          "collect-bodies",
+			ExpectedBody:  `["[1, 2]","[3]","[{\"id\": 4}]"]`,
+			ExpectedPages: "3",
+		},
+		"max pages": {
+			MaxPages:      2,
+			Mode:          "concat-json-arrays",
+			ExpectedBody:  `[1,2,3]`,
+			ExpectedPages: "2",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			testHttpMock := setUpMockHttpServer()
+
+			defer testHttpMock.server.Close()
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_paginate, testHttpMock.server.URL, 200, tc.MaxPages, tc.Mode),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.ExpectedBody {
+								return fmt.Errorf(
+									`'body' output is %s; want %s`,
+									outputs["body"].Value,
+									tc.ExpectedBody,
+								)
+							}
+
+							if outputs["pages_fetched"].Value != tc.ExpectedPages {
+								return fmt.Errorf(
+									`'pages_fetched' output is %s; want %s`,
+									outputs["pages_fetched"].Value,
+									tc.ExpectedPages,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_requestBodyFile = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+  request_method = "POST"
+  request_body_file = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_requestBodyFile(t *testing.T) {
 	testHttpMock := setUpMockHttpServer()
 
 	defer testHttpMock.server.Close()
 
+	bodyFile, err := ioutil.TempFile("", "request_body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bodyFile.Name())
+
+	if _, err := bodyFile.WriteString(`{"key": "value"}`); err != nil {
+		t.Fatal(err)
+	}
+	bodyFile.Close()
+
 	resource.UnitTest(t, resource.TestCase{
 		Providers: testProviders,
 		Steps: []resource.TestStep{
 			{
-				Config: fmt.Sprintf(testDataSourceConfig_utf16, testHttpMock.server.URL, 200),
-				// This should now be a warning, but unsure how to test for it...
-				//ExpectWarning: regexp.MustCompile("Content-Type is not a text type. Got: application/json; charset=UTF-16"),
+				Config: fmt.Sprintf(testDataSourceConfig_requestBodyFile, testHttpMock.server.URL, 200, filepath.ToSlash(bodyFile.Name())),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != `1.0.0,POST,{"key": "value"}` {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,POST,{"key": "value"}'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
 			},
 		},
 	})
 }
 
-func setUpMockHttpServer() *TestHttpMock {
-	Server := httptest.NewServer(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func TestDataSource_requestBodyFileStreamed(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
 
-			w.Header().Set("Content-Type", "text/plain")
-			w.Header().Add("X-Single", "foobar")
-			w.Header().Add("X-Double", "1")
-			w.Header().Add("X-Double", "2")
-			if r.URL.Path == "/meta_200.txt" {
-				var body bytes.Buffer
-				body.WriteString("1.0.0")
-				if r.Method == "GET" {
-					body.WriteString(",GET")
-				} else if r.Method == "POST" {
-					buf := new(bytes.Buffer)
-					buf.ReadFrom(r.Body)
-					newStr := buf.String()
-					body.WriteString(fmt.Sprintf(",POST,%s", newStr))
-				}
-				w.WriteHeader(http.StatusOK)
-				w.Write(body.Bytes())
-			} else if r.URL.Path == "/restricted/meta_200.txt" {
-				if r.Header.Get("Authorization") == "Zm9vOmJhcg==" {
+	defer testHttpMock.server.Close()
+
+	const size = 64 << 20
+
+	bodyFile, err := ioutil.TempFile("", "request_body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bodyFile.Name())
+
+	if err := bodyFile.Truncate(size); err != nil {
+		t.Fatal(err)
+	}
+	bodyFile.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url":               fmt.Sprintf("%s/upload/meta_200.txt", testHttpMock.server.URL),
+		"request_method":    "POST",
+		"request_body_file": bodyFile.Name(),
+	})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	diags := dataSourceRead(context.Background(), d, p.Meta())
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	runtime.ReadMemStats(&after)
+
+	// The server saw every byte, with the length given up front.
+	expected := fmt.Sprintf("%d,%d", size, size)
+	if body := d.Get("body").(string); body != expected {
+		t.Fatalf("expected body %s, got %s", expected, body)
+	}
+
+	// Reading the file into memory would allocate at least its size.
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/4 {
+		t.Fatalf("expected the body to be streamed, but %d bytes were allocated", allocated)
+	}
+}
+
+const testDataSourceConfig_blockPrivateIPs = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  block_private_ips     = true
+  allowed_private_cidrs = [%s]
+
+  retry {
+    attempts     = 3
+    min_delay_ms = 10
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_blockPrivateIPs(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_blockPrivateIPs, testHttpMock.server.URL, 200, ""),
+				ExpectError: regexp.MustCompile(`Connection to loopback address 127\.0\.0\.1 is blocked`),
+			},
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_blockPrivateIPs, testHttpMock.server.URL, 200, `"127.0.0.0/8"`),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_localAddress = `
+data "http" "http_test" {
+  url = "%s/remote-addr/meta_%d.txt"
+
+  local_address = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_localAddress(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_localAddress, testHttpMock.server.URL, 200, "127.0.0.1"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "127.0.0.1" {
+						return fmt.Errorf(
+							`'body' output is %s; want '127.0.0.1'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_localAddress, testHttpMock.server.URL, 200, "localhost"),
+				ExpectError: regexp.MustCompile(`expected local_address to be an IP address`),
+			},
+		},
+	})
+}
+
+func TestDataSource_expectContinue(t *testing.T) {
+	// The server rejects the expectation after reading the request headers,
+	// then records anything the client sends before closing the connection.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	type received struct {
+		header string
+		rest   []byte
+	}
+	receivedCh := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var r received
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			r.header += line
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+
+		conn.Write([]byte("HTTP/1.1 417 Expectation Failed\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		r.rest, _ = ioutil.ReadAll(reader)
+		receivedCh <- r
+	}()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url":                        fmt.Sprintf("http://%s/meta_200.txt", listener.Addr()),
+		"request_method":             "POST",
+		"request_body":               "mytest",
+		"expect_continue_timeout_ms": 10000,
+	})
+
+	start := time.Now()
+	diags := dataSourceRead(context.Background(), d, p.Meta())
+	if !diags.HasError() || !strings.Contains(diags[len(diags)-1].Summary, "Response code: 417") {
+		t.Fatalf("expected a 417 response, got %v", diags)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the rejection to end the request, took %s", elapsed)
+	}
+
+	r := <-receivedCh
+	if !strings.Contains(r.header, "Expect: 100-continue\r\n") {
+		t.Fatalf("expected an Expect: 100-continue header, got %q", r.header)
+	}
+	if len(r.rest) > 0 {
+		t.Fatalf("expected the body not to be sent, got %q", r.rest)
+	}
+
+	// A server that accepts the expectation receives the body.
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	d = schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url":                        fmt.Sprintf("%s/meta_200.txt", testHttpMock.server.URL),
+		"request_method":             "POST",
+		"request_body":               "mytest",
+		"expect_continue_timeout_ms": 10000,
+	})
+
+	if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if body := d.Get("body").(string); body != "1.0.0,POST,mytest" {
+		t.Fatalf("expected body %q, got %q", "1.0.0,POST,mytest", body)
+	}
+}
+
+func TestDataSource_validateOnly(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(newMockHttpHandler())
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+
+	defer server.Close()
+
+	cases := map[string]struct {
+		ProviderRaw map[string]interface{}
+		ExpectError string
+	}{
+		"valid": {
+			ProviderRaw: map[string]interface{}{},
+		},
+		"host not allowed": {
+			ProviderRaw: map[string]interface{}{"allowed_hosts": []interface{}{"example.com"}},
+			ExpectError: "Host 127.0.0.1 is not allowed",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(tc.ProviderRaw)); diags.HasError() {
+				t.Fatalf("unexpected error configuring provider: %v", diags)
+			}
+
+			d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+				"url":              server.URL + "/meta_200.txt",
+				"request_method":   "POST",
+				"request_body":     "hello",
+				"request_headers":  map[string]interface{}{"X-Test": "value"},
+				"query_parameters": map[string]interface{}{"a": "b"},
+				"basic_auth": []interface{}{
+					map[string]interface{}{"username": "user", "password": "secret"},
+				},
+				"debug":         true,
+				"validate_only": true,
+			})
+
+			diags := dataSourceRead(context.Background(), d, p.Meta())
+
+			if n := atomic.LoadInt32(&connections); n != 0 {
+				t.Fatalf("expected no connections, got %d", n)
+			}
+
+			if tc.ExpectError != "" {
+				if !diags.HasError() || diags[0].Summary != tc.ExpectError {
+					t.Fatalf("expected error %q, got %v", tc.ExpectError, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if !d.Get("validated").(bool) {
+				t.Fatal("expected validated to be true")
+			}
+
+			sent := d.Get("sent_request_headers").(map[string]interface{})
+			expected := map[string]string{
+				"X-Test":        "value",
+				"Authorization": "REDACTED",
+			}
+			for name, want := range expected {
+				if sent[name] != want {
+					t.Fatalf("expected sent %s header %q, got %v", name, want, sent[name])
+				}
+			}
+
+			dump := d.Get("request_dump").(string)
+			if !strings.HasPrefix(dump, "POST /meta_200.txt?a=b HTTP/1.1\r\n") || !strings.HasSuffix(dump, "\r\n\r\nhello") {
+				t.Fatalf("expected the request to be dumped, got %q", dump)
+			}
+		})
+	}
+}
+
+func TestDataSource_jwt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server accepts the request only with a bearer token signed by the
+	// key, echoing its claims.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		_, claims, err := verifyJWT(token, &key.PublicKey)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(claims)
+	}))
+	defer server.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url": server.URL,
+		"jwt": []interface{}{
+			map[string]interface{}{
+				"signing_key": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+				"claims":      map[string]interface{}{"sub": "terraform", "aud": "api"},
+				"ttl_ms":      60000,
+			},
+		},
+	})
+
+	before := time.Now().Unix()
+	if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if status := d.Get("status_code").(int); status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", status, d.Get("body"))
+	}
+
+	var claims struct {
+		Subject   string `json:"sub"`
+		Audience  string `json:"aud"`
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+	}
+	if err := json.Unmarshal([]byte(d.Get("body").(string)), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "terraform" || claims.Audience != "api" {
+		t.Fatalf("expected the configured claims, got %s", d.Get("body"))
+	}
+	if claims.IssuedAt < before || claims.ExpiresAt != claims.IssuedAt+60 {
+		t.Fatalf("expected iat to be now and exp a minute later, got %s", d.Get("body"))
+	}
+}
+
+const testDataSourceConfig_graphql = `
+data "http" "http_test" {
+  url = "%s/graphql/meta_%d.txt"
+
+  graphql {
+    query     = "%s"
+    variables = jsonencode({ id = "1" })
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_graphql(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_graphql, testHttpMock.server.URL, 200, "query Node($id: ID!) { node(id: $id) { id } }"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					// The mock echoes the request it received.
+					expected := `{"data":{"method":"POST","query":"query Node($id: ID!) { node(id: $id) { id } }","variables":{"id":"1"}}}`
+					if outputs["body"].Value != expected {
+						return fmt.Errorf(
+							`'body' output is %s; want '%s'`,
+							outputs["body"].Value,
+							expected,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_graphqlErrors(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_graphql, testHttpMock.server.URL, 200, "{ unknownField }"),
+				ExpectError: regexp.MustCompile(`GraphQL error: Cannot query field "unknownField"`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_multipart = `
+data "http" "http_test" {
+  url = "%s/multipart/meta_%d.txt"
+  request_method = "POST"
+
+  multipart {
+    field {
+      name  = "description"
+      value = "a test upload"
+    }
+
+    field {
+      name      = "upload"
+      file_path = "%s"
+    }
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_multipart(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	uploadFile, err := ioutil.TempFile("", "multipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(uploadFile.Name())
+
+	if _, err := uploadFile.Write(bytes.Repeat([]byte("a"), 2048)); err != nil {
+		t.Fatal(err)
+	}
+	uploadFile.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_multipart, testHttpMock.server.URL, 200, filepath.ToSlash(uploadFile.Name())),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "description,upload:2048" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'description,upload:2048'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_allowBodyOnGet = `
+data "http" "http_test" {
+  url          = "%s/body/meta_%d.txt"
+  request_body = "mytest"
+
+  allow_body_on_get = %t
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_allowBodyOnGet(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		AllowBodyOnGet bool
+		Expected       string
+	}{
+		"allowed": {
+			AllowBodyOnGet: true,
+			Expected:       "GET,mytest",
+		},
+		"default": {
+			AllowBodyOnGet: false,
+			Expected:       "GET,",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_allowBodyOnGet, testHttpMock.server.URL, 200, tc.AllowBodyOnGet),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_method = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+  request_method = "%s"
+  request_body = "mytest"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_put_http200(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_method, testHttpMock.server.URL, 200, "PUT"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,PUT,mytest" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,PUT,mytest'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_patch_http200(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_method, testHttpMock.server.URL, 200, "PATCH"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,PATCH,mytest" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,PATCH,mytest'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_invalidMethod(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_method, testHttpMock.server.URL, 200, "GETT"),
+				ExpectError: regexp.MustCompile(`expected request_method to be one of`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_expectedStatusCodes = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  expected_status_codes = [404]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+output "status" {
+  value = data.http.http_test.status
+}
+`
+
+func TestDataSource_expectedStatusCodes404(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_expectedStatusCodes, testHttpMock.server.URL, 404),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["status_code"].Value != "404" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '404'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					if outputs["status"].Value != "404 Not Found" {
+						return fmt.Errorf(
+							`'status' output is %s; want '404 Not Found'`,
+							outputs["status"].Value,
+						)
+					}
+
+					if outputs["body"].Value != "" {
+						return fmt.Errorf(
+							`'body' output is %s; want ''`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_expectedStatusCodes200(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_expectedStatusCodes, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("HTTP request error. Response code: 200"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_failOnEmptyBody = `
+data "http" "http_test" {
+  url = "%s/%smeta_%d.txt"
+
+  fail_on_empty_body = %t
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_failOnEmptyBody(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Path          string
+		FailOnEmpty   bool
+		ExpectedBody  string
+		ExpectedError *regexp.Regexp
+	}{
+		"empty": {
+			Path:          "empty/",
+			FailOnEmpty:   true,
+			ExpectedError: regexp.MustCompile("Response body is empty"),
+		},
+		"empty allowed": {
+			Path:         "empty/",
+			FailOnEmpty:  false,
+			ExpectedBody: "",
+		},
+		"not empty": {
+			Path:         "",
+			FailOnEmpty:  true,
+			ExpectedBody: "1.0.0,GET",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config:      fmt.Sprintf(testDataSourceConfig_failOnEmptyBody, testHttpMock.server.URL, tc.Path, 200, tc.FailOnEmpty),
+						ExpectError: tc.ExpectedError,
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.ExpectedBody {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.ExpectedBody,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_requestTimeout = `
+data "http" "http_test" {
+  url = "%s/slow/meta_%d.txt"
+
+  request_timeout_ms = 10
+}
+`
+
+func TestDataSource_requestTimeout(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_requestTimeout, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("HTTP request timed out after 10ms"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_responseTime = `
+data "http" "http_test" {
+  url = "%s/slow/meta_%d.txt"
+}
+
+output "response_time_ms" {
+  value = data.http.http_test.response_time_ms
+}
+`
+
+func TestDataSource_responseTime(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_responseTime, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					responseTime, err := strconv.Atoi(outputs["response_time_ms"].Value.(string))
+					if err != nil {
+						return fmt.Errorf("error parsing 'response_time_ms' output: %s", err)
+					}
+
+					if responseTime < 100 {
+						return fmt.Errorf(
+							`'response_time_ms' output is %d; want at least 100`,
+							responseTime,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_retry = `
+data "http" "http_test" {
+  url = "%s/retry/meta_%d.txt"
+
+  retry {
+    attempts     = %d
+    min_delay_ms = 10
+    max_delay_ms = 50
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_retry(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_retry, testHttpMock.server.URL, 200, 2),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_retryExhausted(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_retry, testHttpMock.server.URL, 200, 1),
+				ExpectError: regexp.MustCompile(`HTTP request error. Response code: 503 \(2 attempts made\)`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_retryStatusCodes = `
+data "http" "http_test" {
+  url = "%s/retry/conflict/meta_%d.txt"
+
+  retry {
+    attempts     = 2
+    min_delay_ms = 10
+    max_delay_ms = 50
+  }
+
+  retry_status_codes = [%s]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_retryStatusCodes(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_retryStatusCodes, testHttpMock.server.URL, 200, "409"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_retryStatusCodesUnlisted(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_retryStatusCodes, testHttpMock.server.URL, 200, "425"),
+				ExpectError: regexp.MustCompile(`HTTP request error. Response code: 409`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_retryAfter = `
+data "http" "http_test" {
+  url = "%s/retry-after/meta_%d.txt"
+
+  retry {
+    attempts     = 1
+    min_delay_ms = 0
+    max_delay_ms = 0
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "response_time_ms" {
+  value = data.http.http_test.response_time_ms
+}
+`
+
+func TestDataSource_retryAfter(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_retryAfter, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					// The retry waits for the Retry-After delay of 1 second
+					// rather than the configured delay of 0.
+					responseTime, err := strconv.Atoi(outputs["response_time_ms"].Value.(string))
+					if err != nil {
+						return fmt.Errorf("error parsing 'response_time_ms' output: %s", err)
+					}
+
+					if responseTime < 1000 {
+						return fmt.Errorf(
+							`'response_time_ms' output is %d; want at least 1000`,
+							responseTime,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_disableKeepAlives = `
+data "http" "http_test" {
+  url = "%s/connections/meta_%d.txt"
+
+  disable_keep_alives = %t
+
+  retry {
+    attempts     = 1
+    min_delay_ms = 0
+    max_delay_ms = 0
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_disableKeepAlives(t *testing.T) {
+	// The mock fails the first request, so the retry either reuses the
+	// connection or opens a second one.
+	cases := map[bool]string{
+		false: "1",
+		true:  "2",
+	}
+
+	for disable, connections := range cases {
+		testHttpMock := setUpMockHttpServer()
+
+		resource.UnitTest(t, resource.TestCase{
+			Providers: testProviders,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(testDataSourceConfig_disableKeepAlives, testHttpMock.server.URL, 200, disable),
+					Check: func(s *terraform.State) error {
+						_, ok := s.RootModule().Resources["data.http.http_test"]
+						if !ok {
+							return fmt.Errorf("missing data resource")
+						}
+
+						outputs := s.RootModule().Outputs
+
+						if outputs["body"].Value != connections {
+							return fmt.Errorf(
+								`'body' output is %s; want '%s'`,
+								outputs["body"].Value,
+								connections,
+							)
+						}
+
+						return nil
+					},
+				},
+			},
+		})
+
+		testHttpMock.server.Close()
+	}
+}
+
+const testDataSourceConfig_clientCert = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+
+  client_cert_pem = <<EOT
+%s
+EOT
+
+  client_key_pem = <<EOT
+%s
+EOT
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_clientCert(t *testing.T) {
+	clientCert := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.cert)
+
+	testHttpMock := setUpMockHttpsServer(&tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	})
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_clientCert, testHttpMock.server.URL, 200, clientCert.certPEM, clientCert.keyPEM),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_insecureSkipVerify = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+}
+`
+
+const testDataSourceConfig_disableHttp2 = `
+data "http" "http_test" {
+  url = "%s/proto/meta_%d.txt"
+
+  insecure_skip_verify = true
+  disable_http2        = %t
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_disableHttp2(t *testing.T) {
+	testHttpMock := setUpMockHttp2Server()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[bool]string{
+		false: "HTTP/2.0",
+		true:  "HTTP/1.1",
+	}
+
+	for disable, proto := range cases {
+		resource.UnitTest(t, resource.TestCase{
+			Providers: testProviders,
+			Steps: []resource.TestStep{
+				{
+					Config: fmt.Sprintf(testDataSourceConfig_disableHttp2, testHttpMock.server.URL, 200, disable),
+					Check: func(s *terraform.State) error {
+						_, ok := s.RootModule().Resources["data.http.http_test"]
+						if !ok {
+							return fmt.Errorf("missing data resource")
+						}
+
+						outputs := s.RootModule().Outputs
+
+						if outputs["body"].Value != proto {
+							return fmt.Errorf(
+								`'body' output is %s; want '%s'`,
+								outputs["body"].Value,
+								proto,
+							)
+						}
+
+						return nil
+					},
+				},
+			},
+		})
+	}
+}
+
+const testDataSourceConfig_tlsCipherSuites = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+  tls_cipher_suites    = ["%s"]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_tlsCipherSuites(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(&tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	})
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_tlsCipherSuites, testHttpMock.server.URL, 200, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_tlsCipherSuites, testHttpMock.server.URL, 200, "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"),
+				ExpectError: regexp.MustCompile("handshake failure"),
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_tlsCipherSuites, testHttpMock.server.URL, 200, "TLS_RSA_WITH_RC4_128_SHA"),
+				ExpectError: regexp.MustCompile(`expected tls_cipher_suites.0 to be one of`),
+			},
+		},
+	})
+}
+
+func TestDataSource_clientCertMissing(t *testing.T) {
+	clientCert := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, nil)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert.cert)
+
+	testHttpMock := setUpMockHttpsServer(&tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	})
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_insecureSkipVerify, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("Error making request"),
+			},
+		},
+	})
+}
+
+func TestDataSource_insecureSkipVerify(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(nil)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_insecureSkipVerify, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_insecureSkipVerifyDisabled(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(nil)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_basic, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("certificate signed by unknown authority"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_caCert = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  ca_cert_pem = <<EOT
+%s
+EOT
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func setUpMockHttpsServerWithCA(t *testing.T) (*TestHttpMock, *testCertificate) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}, nil)
+
+	serverCert := newTestCertificate(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "127.0.0.1"},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}, ca)
+
+	keyPair, err := tls.X509KeyPair([]byte(serverCert.certPEM), []byte(serverCert.keyPEM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return setUpMockHttpsServer(&tls.Config{Certificates: []tls.Certificate{keyPair}}), ca
+}
+
+func TestDataSource_caCert(t *testing.T) {
+	testHttpMock, ca := setUpMockHttpsServerWithCA(t)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_caCert, testHttpMock.server.URL, 200, ca.certPEM),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_pinSHA256 = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  pin_sha256 = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_pinSHA256(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(nil)
+
+	defer testHttpMock.server.Close()
+
+	spki := sha256.Sum256(testHttpMock.server.Certificate().RawSubjectPublicKeyInfo)
+	wrong := sha256.Sum256([]byte("wrong"))
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_pinSHA256, testHttpMock.server.URL, 200, base64.StdEncoding.EncodeToString(spki[:])),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_pinSHA256, testHttpMock.server.URL, 200, hex.EncodeToString(wrong[:])),
+				ExpectError: regexp.MustCompile("the server certificate does not match pin_sha256"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_tlsCert = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  ca_cert_pem = <<EOT
+%s
+EOT
+}
+
+output "tls_cert_not_after" {
+  value = data.http.http_test.tls_cert_not_after
+}
+
+output "tls_cert_issuer" {
+  value = data.http.http_test.tls_cert_issuer
+}
+
+output "tls_cert_subject" {
+  value = data.http.http_test.tls_cert_subject
+}
+`
+
+func TestDataSource_tlsCert(t *testing.T) {
+	testHttpMock, ca := setUpMockHttpsServerWithCA(t)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_tlsCert, testHttpMock.server.URL, 200, ca.certPEM),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["tls_cert_issuer"].Value != "CN=Test CA" {
+						return fmt.Errorf(
+							`'tls_cert_issuer' output is %s; want 'CN=Test CA'`,
+							outputs["tls_cert_issuer"].Value,
+						)
+					}
+
+					if outputs["tls_cert_subject"].Value != "CN=127.0.0.1" {
+						return fmt.Errorf(
+							`'tls_cert_subject' output is %s; want 'CN=127.0.0.1'`,
+							outputs["tls_cert_subject"].Value,
+						)
+					}
+
+					notAfter, err := time.Parse(time.RFC3339, outputs["tls_cert_not_after"].Value.(string))
+					if err != nil {
+						return fmt.Errorf("'tls_cert_not_after' output is not RFC 3339: %s", err)
+					}
+					if !notAfter.After(time.Now()) {
+						return fmt.Errorf("'tls_cert_not_after' output is %s; want a time in the future", notAfter)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_usedTLS = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+}
+
+output "used_tls" {
+  value = data.http.http_test.used_tls
+}
+`
+
+func TestDataSource_usedTLS(t *testing.T) {
+	cases := map[string]struct {
+		Server   func() *TestHttpMock
+		Expected string
+	}{
+		"http": {
+			Server:   setUpMockHttpServer,
+			Expected: "false",
+		},
+		"https": {
+			Server:   func() *TestHttpMock { return setUpMockHttpsServer(nil) },
+			Expected: "true",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			testHttpMock := tc.Server()
+
+			defer testHttpMock.server.Close()
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_usedTLS, testHttpMock.server.URL, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["used_tls"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'used_tls' output is %s; want '%s'`,
+									outputs["used_tls"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_responseProtocol = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+}
+
+output "response_protocol" {
+  value = data.http.http_test.response_protocol
+}
+`
+
+func TestDataSource_responseProtocol(t *testing.T) {
+	cases := map[string]struct {
+		Server   func() *TestHttpMock
+		Expected string
+	}{
+		"http": {
+			Server:   setUpMockHttpServer,
+			Expected: "HTTP/1.1",
+		},
+		"http2": {
+			Server:   setUpMockHttp2Server,
+			Expected: "HTTP/2.0",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			testHttpMock := tc.Server()
+
+			defer testHttpMock.server.Close()
+
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_responseProtocol, testHttpMock.server.URL, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["response_protocol"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'response_protocol' output is %s; want '%s'`,
+									outputs["response_protocol"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_tlsVersion = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  insecure_skip_verify = true
+  tls_min_version      = "%s"
+  tls_max_version      = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_tlsVersion(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(&tls.Config{MinVersion: tls.VersionTLS13})
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_tlsVersion, testHttpMock.server.URL, 200, "1.3", "1.3"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_tlsVersionUnsupported(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(&tls.Config{MinVersion: tls.VersionTLS13})
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_tlsVersion, testHttpMock.server.URL, 200, "1.2", "1.2"),
+				ExpectError: regexp.MustCompile("protocol version not supported"),
+			},
+		},
+	})
+}
+
+func TestDataSource_tlsVersionInvalid(t *testing.T) {
+	testHttpMock := setUpMockHttpsServer(nil)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_tlsVersion, testHttpMock.server.URL, 200, "1.2", "2.0"),
+				ExpectError: regexp.MustCompile(`expected tls_max_version to be one of \[1.0, 1.1, 1.2, 1.3\], got "2.0"`),
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_tlsVersion, testHttpMock.server.URL, 200, "1.3", "1.2"),
+				ExpectError: regexp.MustCompile("tls_min_version must be less than or equal to tls_max_version"),
+			},
+		},
+	})
+}
+
+func TestDataSource_caCertMissing(t *testing.T) {
+	testHttpMock, _ := setUpMockHttpsServerWithCA(t)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_basic, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("certificate signed by unknown authority"),
+			},
+		},
+	})
+}
+
+func TestDataSource_caCertInvalid(t *testing.T) {
+	testHttpMock, _ := setUpMockHttpsServerWithCA(t)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_caCert, testHttpMock.server.URL, 200, "not a certificate"),
+				ExpectError: regexp.MustCompile("Error parsing ca_cert_pem"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_queryParameters = `
+data "http" "http_test" {
+  url = "%s/query/meta_%d.txt?x=1"
+
+  query_parameters = {
+    "a b" = "c&d"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_queryParameters(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_queryParameters, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "x=1&a+b=c%26d" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'x=1&a+b=c%%26d'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_pathSegments = `
+data "http" "http_test" {
+  url = "%s/path-segments/?x=1"
+
+  path_segments = ["a b", "c/d", "e?f#g", "100%%"]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_pathSegments(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_pathSegments, testHttpMock.server.URL),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					want := "/path-segments/a%20b/c%2Fd/e%3Ff%23g/100%25?x=1"
+					if outputs["body"].Value != want {
+						return fmt.Errorf(
+							`'body' output is %s; want '%s'`,
+							outputs["body"].Value,
+							want,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_json = `
+data "http" "http_test" {
+  url = "%s/json/meta_%d.txt"
+}
+
+output "response_body_json" {
+  value = data.http.http_test.response_body_json
+}
+
+output "version" {
+  value = jsondecode(data.http.http_test.response_body_json).version
+}
+`
+
+func TestDataSource_json(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_json, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["response_body_json"].Value != `{"tags":[1,2.5],"version":"1.0.0"}` {
+						return fmt.Errorf(
+							`'response_body_json' output is %s; want '{"tags":[1,2.5],"version":"1.0.0"}'`,
+							outputs["response_body_json"].Value,
+						)
+					}
+
+					if outputs["version"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'version' output is %s; want '1.0.0'`,
+							outputs["version"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_requestBodyJSON = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_body_json = jsonencode({
+    name = "test"
+    tags = [1, 2]
+  })
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+const testDataSourceConfig_requestBodyJSONContentType = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_headers = {
+    "Content-Type" = "application/vnd.api+json"
+  }
+
+  request_body_json = "{}"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_requestBodyJSON(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Config   string
+		Expected string
+	}{
+		"encoded": {
+			Config:   testDataSourceConfig_requestBodyJSON,
+			Expected: `application/json,{"name":"test","tags":[1,2]}`,
+		},
+		"content type": {
+			Config:   testDataSourceConfig_requestBodyJSONContentType,
+			Expected: `application/vnd.api+json,{}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(tc.Config, testHttpMock.server.URL, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_requestBodyJSONInvalid = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_body_json = "{not json"
+}
+`
+
+func TestDataSource_requestBodyJSONInvalid(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_requestBodyJSONInvalid, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("expected request_body_json to be valid JSON"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_refererOrigin = `
+data "http" "http_test" {
+  url = "%s/referer-origin/meta_%d.txt"
+
+  referer = "https://example.com/page"
+  origin  = "https://example.com"
+
+  request_headers = {
+    %s
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_refererOrigin(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := []struct {
+		Name           string
+		RequestHeaders string
+		ExpectBody     string
+	}{
+		{
+			Name:       "attributes",
+			ExpectBody: "https://example.com/page,https://example.com",
+		},
+		{
+			Name:           "request headers take precedence",
+			RequestHeaders: `"Origin" = "https://other.example.com"`,
+			ExpectBody:     "https://example.com/page,https://other.example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_refererOrigin, testHttpMock.server.URL, 200, tc.RequestHeaders),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.ExpectBody {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.ExpectBody,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_bodyTemplate = `
+data "http" "http_test" {
+  url            = "%s/meta_%d.txt"
+  request_method = "POST"
+
+  body_template = "{{.%s}}"
+  body_vars = {
+    name = "x"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_bodyTemplate(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_bodyTemplate, testHttpMock.server.URL, 200, "name"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,POST,x" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,POST,x'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_bodyTemplate, testHttpMock.server.URL, 200, "nmae"),
+				ExpectError: regexp.MustCompile(`Error rendering body_template: .*map has no entry for key "nmae"`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_requestContentType = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_headers = {
+    "Content-Type" = "text/plain"
+  }
+
+  request_content_type = "application/xml"
+  request_body         = "<a/>"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+const testDataSourceConfig_requestContentTypeJSON = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_content_type = "application/merge-patch+json"
+  request_body_json    = "{}"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_requestContentType(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Config   string
+		Expected string
+	}{
+		"request headers": {
+			Config:   testDataSourceConfig_requestContentType,
+			Expected: `application/xml,<a/>`,
+		},
+		"request body json": {
+			Config:   testDataSourceConfig_requestContentTypeJSON,
+			Expected: `application/merge-patch+json,{}`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(tc.Config, testHttpMock.server.URL, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_requestContentTypeMultipart = `
+data "http" "http_test" {
+  url            = "%s/content-type/meta_%d.txt"
+  request_method = "POST"
+
+  request_content_type = "application/xml"
+
+  multipart {
+    field {
+      name  = "name"
+      value = "test"
+    }
+  }
+}
+`
+
+func TestDataSource_requestContentTypeMultipart(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_requestContentTypeMultipart, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile(`"request_content_type": conflicts with multipart`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_accept = `
+data "http" "http_test" {
+  url = "%s/accept/meta_%d.txt"
+
+  request_headers = {
+    Accept = "text/plain"
+  }
+
+  accept          = "application/vnd.test+json"
+  accept_encoding = "identity"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_accept(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_accept, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "application/vnd.test+json,identity" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'application/vnd.test+json,identity'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_acceptEncoding = `
+data "http" "http_test" {
+  url = "%s/gzip/meta_%d.txt"
+  %s
+}
+
+output "body_base64" {
+  value = data.http.http_test.body_base64
+}
+`
+
+func TestDataSource_acceptEncoding(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Argument string
+		Expected []byte
+	}{
+		// The default gzip encoding is decompressed.
+		"default": {
+			Argument: "",
+			Expected: []byte("1.0.0"),
+		},
+		// An explicit encoding leaves the body compressed.
+		"gzip": {
+			Argument: `accept_encoding = "gzip"`,
+			Expected: []byte{0x1f, 0x8b},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_acceptEncoding, testHttpMock.server.URL, 200, tc.Argument),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							body, err := base64.StdEncoding.DecodeString(outputs["body_base64"].Value.(string))
+							if err != nil {
+								return err
+							}
+
+							if !bytes.HasPrefix(body, tc.Expected) {
+								return fmt.Errorf("body is %x; want a body starting with %x", body, tc.Expected)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_contentLength = `
+data "http" "http_test" {
+  url = "%s/%smeta_%d.txt"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "content_length" {
+  value = data.http.http_test.content_length
+}
+`
+
+func TestDataSource_contentLength(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Path     string
+		Expected string
+	}{
+		"content length header": {
+			Path:     "",
+			Expected: "1.0.0,GET",
+		},
+		"chunked": {
+			Path:     "chunked/",
+			Expected: "1.0.0,chunked",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_contentLength, testHttpMock.server.URL, tc.Path, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.Expected,
+								)
+							}
+
+							want := strconv.Itoa(len(tc.Expected))
+							if outputs["content_length"].Value != want {
+								return fmt.Errorf(
+									`'content_length' output is %s; want '%s'`,
+									outputs["content_length"].Value,
+									want,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_xml = `
+data "http" "http_test" {
+  url = "%s/xml/meta_%d.txt"
+}
+
+output "response_body_xml" {
+  value = data.http.http_test.response_body_xml
+}
+
+output "currency" {
+  value = jsondecode(data.http.http_test.response_body_xml).release.price["@currency"]
+}
+`
+
+func TestDataSource_xml(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_xml, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					want := `{"release":{"@id":"1","price":{"#text":"10","@currency":"EUR"},"tag":["a","b"],"version":"1.0.0"}}`
+					if outputs["response_body_xml"].Value != want {
+						return fmt.Errorf(
+							`'response_body_xml' output is %s; want '%s'`,
+							outputs["response_body_xml"].Value,
+							want,
+						)
+					}
+
+					if outputs["currency"].Value != "EUR" {
+						return fmt.Errorf(
+							`'currency' output is %s; want 'EUR'`,
+							outputs["currency"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_requestID = `
+data "http" "http_test" {
+  url = "%s/request-id/meta_%d.txt"
+
+  request_id_header = "X-Request-ID"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "request_id" {
+  value = data.http.http_test.request_id
+}
+`
+
+const testDataSourceConfig_requestIDHeaders = `
+data "http" "http_test" {
+  url = "%s/request-id/meta_%d.txt"
+
+  request_headers = {
+    "X-Request-ID" = "from-headers"
+  }
+
+  request_id_header = "X-Request-ID"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "request_id" {
+  value = data.http.http_test.request_id
+}
+`
+
+func TestDataSource_requestID(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Config   string
+		Expected *regexp.Regexp
+	}{
+		"generated": {
+			Config:   testDataSourceConfig_requestID,
+			Expected: testUUID,
+		},
+		"request headers": {
+			Config:   testDataSourceConfig_requestIDHeaders,
+			Expected: regexp.MustCompile(`^from-headers$`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(tc.Config, testHttpMock.server.URL, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							requestID := outputs["request_id"].Value.(string)
+							if !tc.Expected.MatchString(requestID) {
+								return fmt.Errorf(
+									`'request_id' output is %s; want a match for %s`,
+									requestID,
+									tc.Expected,
+								)
+							}
+
+							if outputs["body"].Value != requestID {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									requestID,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+// testUUID matches a version 4 UUID.
+var testUUID = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewRequestID(t *testing.T) {
+	first, err := newRequestID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newRequestID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !testUUID.MatchString(first) {
+		t.Fatalf("expected a version 4 UUID, got %s", first)
+	}
+	if first == second {
+		t.Fatalf("expected different IDs, got %s twice", first)
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	cases := map[string]struct {
+		Input     string
+		Expected  string
+		ExpectErr bool
+	}{
+		"object":      {Input: `{ "b": 1, "a": [true, null] }`, Expected: `{"a":[true,null],"b":1}`},
+		"big number":  {Input: `12345678901234567890`, Expected: `12345678901234567890`},
+		"string":      {Input: `"x"`, Expected: `"x"`},
+		"invalid":     {Input: `{"a":`, ExpectErr: true},
+		"trailing":    {Input: `{} {}`, ExpectErr: true},
+		"empty input": {Input: ``, ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := normalizeJSON([]byte(tc.Input))
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestAddQueryParameters(t *testing.T) {
+	cases := map[string]struct {
+		URL        string
+		Parameters map[string]interface{}
+		Expected   string
+		ExpectErr  bool
+	}{
+		"no query":       {URL: "http://example.com/api", Parameters: map[string]interface{}{"a b": "c&d"}, Expected: "http://example.com/api?a+b=c%26d"},
+		"existing query": {URL: "http://example.com/api?z=%2F&y", Parameters: map[string]interface{}{"a b": "c&d"}, Expected: "http://example.com/api?z=%2F&y&a+b=c%26d"},
+		"sorted by name": {URL: "http://example.com/api?x=1", Parameters: map[string]interface{}{"b": "2", "a": "1"}, Expected: "http://example.com/api?x=1&a=1&b=2"},
+		"trailing &":     {URL: "http://example.com/api?x=1&", Parameters: map[string]interface{}{"a": "1"}, Expected: "http://example.com/api?x=1&a=1"},
+		"repeated name":  {URL: "http://example.com/api?a=1", Parameters: map[string]interface{}{"a": "2"}, Expected: "http://example.com/api?a=1&a=2"},
+		"fragment":       {URL: "http://example.com/api?x=1#top", Parameters: map[string]interface{}{"a": "1"}, Expected: "http://example.com/api?x=1&a=1#top"},
+		"invalid url":    {URL: "http://example.com/%zz", Parameters: map[string]interface{}{"a": "1"}, ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := addQueryParameters(tc.URL, tc.Parameters)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestAddPathSegments(t *testing.T) {
+	cases := map[string]struct {
+		URL       string
+		Segments  []interface{}
+		Expected  string
+		ExpectErr bool
+	}{
+		"segments":            {URL: "http://example.com/api", Segments: []interface{}{"a b", "c/d"}, Expected: "http://example.com/api/a%20b/c%2Fd"},
+		"trailing slash":      {URL: "http://example.com/api/", Segments: []interface{}{"a"}, Expected: "http://example.com/api/a"},
+		"no path":             {URL: "http://example.com", Segments: []interface{}{"a"}, Expected: "http://example.com/a"},
+		"escaped base path":   {URL: "http://example.com/x%2Fy", Segments: []interface{}{"a"}, Expected: "http://example.com/x%2Fy/a"},
+		"query and fragment":  {URL: "http://example.com/api?x=1#top", Segments: []interface{}{"a?b#c"}, Expected: "http://example.com/api/a%3Fb%23c?x=1#top"},
+		"percent not doubled": {URL: "http://example.com", Segments: []interface{}{"100%"}, Expected: "http://example.com/100%25"},
+		"unicode":             {URL: "http://example.com", Segments: []interface{}{"café"}, Expected: "http://example.com/caf%C3%A9"},
+		"invalid url":         {URL: "http://example.com/%zz", Segments: []interface{}{"a"}, ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := addPathSegments(tc.URL, tc.Segments)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsContentTypeExpected(t *testing.T) {
+	cases := map[string]struct {
+		ContentType string
+		Expected    string
+		Match       bool
+	}{
+		"unset":       {ContentType: "text/plain", Expected: "", Match: true},
+		"exact":       {ContentType: "application/json", Expected: "application/json", Match: true},
+		"prefix":      {ContentType: "application/json; charset=utf-8", Expected: "application/json", Match: true},
+		"case":        {ContentType: "Application/JSON", Expected: "application/json", Match: true},
+		"mismatch":    {ContentType: "text/html", Expected: "application/json", Match: false},
+		"missing":     {ContentType: "", Expected: "application/json", Match: false},
+		"longer want": {ContentType: "text/plain", Expected: "text/plain; charset=utf-8", Match: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := isContentTypeExpected(tc.ContentType, tc.Expected); actual != tc.Match {
+				t.Fatalf("expected %t, got %t", tc.Match, actual)
+			}
+		})
+	}
+}
+
+const testDataSourceConfig_expectedContentType = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  expected_content_type = "application/json"
+}
+`
+
+func TestDataSource_expectedContentType(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_expectedContentType, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile(`Content-Type "text/plain" does not match\s+expected_content_type "application/json"`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_bodyURLDecoded = `
+data "http" "http_test" {
+  url = "%s/urlencoded/%smeta_%d.txt"
+}
+
+output "body_url_decoded" {
+  value = data.http.http_test.body_url_decoded
+}
+`
+
+func TestDataSource_bodyURLDecoded(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Path     string
+		Expected string
+	}{
+		"valid": {
+			Path:     "",
+			Expected: "hello world",
+		},
+		// An invalid escape leaves the attribute empty.
+		"invalid": {
+			Path:     "invalid/",
+			Expected: "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_bodyURLDecoded, testHttpMock.server.URL, tc.Path, 200),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body_url_decoded"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body_url_decoded' output is %s; want '%s'`,
+									outputs["body_url_decoded"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+// A body with an invalid escape leaves body_url_decoded empty with a warning.
+func TestDataSource_bodyURLDecodedInvalid(t *testing.T) {
+	server := httptest.NewServer(newMockHttpHandler())
+	defer server.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url": server.URL + "/urlencoded/invalid/meta_200.txt",
+	})
+
+	diags := dataSourceRead(context.Background(), d, p.Meta())
+	if len(diags) != 1 || diags[0].Severity != diag.Warning {
+		t.Fatalf("expected a single warning, got %v", diags)
+	}
+
+	if diags[0].Summary != "Response body could not be URL decoded" {
+		t.Fatalf("unexpected warning %q", diags[0].Summary)
+	}
+
+	if v := d.Get("body_url_decoded").(string); v != "" {
+		t.Fatalf("expected body_url_decoded to be empty, got %q", v)
+	}
+}
+
+const testDataSourceConfig_jsonPaths = `
+data "http" "http_test" {
+  url = "%s/json/nested/meta_%d.txt"
+
+  json_paths = {
+    name    = "$.service.name"
+    port    = "$.service.ports[1]"
+    primary = "service.endpoints.0.host"
+    tags    = "$.service.tags"
+    missing = "$.service.owner"
+  }
+
+  json_paths_strict = %t
+}
+
+output "json_values" {
+  value = data.http.http_test.json_values
+}
+`
+
+func TestDataSource_jsonPaths(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_jsonPaths, testHttpMock.server.URL, 200, false),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					jsonValues := outputs["json_values"].Value.(map[string]interface{})
+
+					expected := map[string]string{
+						"name":    "api",
+						"port":    "8443",
+						"primary": "api-1.example.com",
+						"tags":    `["a","b"]`,
+						"missing": "",
+					}
+
+					for k, v := range expected {
+						if jsonValues[k] != v {
+							return fmt.Errorf(
+								`'json_values.%s' output is %s; want '%s'`,
+								k,
+								jsonValues[k],
+								v,
+							)
+						}
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_jsonSchema = `
+data "http" "http_test" {
+  url = "%s/json/meta_%d.txt"
+
+  json_schema = jsonencode({
+    type     = "object"
+    required = ["version"]
+    properties = {
+      version = { type = "string" }
+      tags    = { type = "array", items = { type = "%s" } }
+    }
+  })
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_jsonSchema(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_jsonSchema, testHttpMock.server.URL, 200, "number"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					want := `{"version": "1.0.0", "tags": [1, 2.5]}`
+					if outputs["body"].Value != want {
+						return fmt.Errorf(
+							`'body' output is %s; want '%s'`,
+							outputs["body"].Value,
+							want,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_jsonSchema, testHttpMock.server.URL, 200, "integer"),
+				ExpectError: regexp.MustCompile(`does not match json_schema at /tags/1`),
+			},
+		},
+	})
+}
+
+func TestDataSource_jsonPathsStrict(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_jsonPaths, testHttpMock.server.URL, 200, true),
+				ExpectError: regexp.MustCompile(`json_paths: key "missing": path "\$.service.owner" not found`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_bodyRegex = `
+data "http" "http_test" {
+  url = "%s/version/meta_%d.txt"
+
+  body_regex        = "%s"
+  body_regex_strict = %t
+}
+
+output "body_match" {
+  value = data.http.http_test.body_match
+}
+
+output "body_captures" {
+  value = jsonencode(data.http.http_test.body_captures)
+}
+`
+
+func TestDataSource_bodyRegex(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_bodyRegex, testHttpMock.server.URL, 200, `version=((\\d+)\\.\\d+\\.\\d+)`, false),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body_match"].Value != "1.2.3" {
+						return fmt.Errorf(
+							`'body_match' output is %s; want '1.2.3'`,
+							outputs["body_match"].Value,
+						)
+					}
+
+					if outputs["body_captures"].Value != `["1.2.3","1"]` {
+						return fmt.Errorf(
+							`'body_captures' output is %s; want '["1.2.3","1"]'`,
+							outputs["body_captures"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_bodyRegex, testHttpMock.server.URL, 200, `release=(\\S+)`, false),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body_match"].Value != "" {
+						return fmt.Errorf(
+							`'body_match' output is %s; want ''`,
+							outputs["body_match"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_bodyRegex, testHttpMock.server.URL, 200, `release=(\\S+)`, true),
+				ExpectError: regexp.MustCompile(`Error evaluating body_regex: pattern .* does not match the response body`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_responseBodyFile = `
+data "http" "http_test" {
+  url = "%s/binary/meta_%d.txt"
+
+  response_body_file = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "body_sha256" {
+  value = data.http.http_test.body_sha256
+}
+`
+
+func TestDataSource_responseBodyFile(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	dir, err := ioutil.TempDir("", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The missing parent directory is created.
+	bodyFile := filepath.Join(dir, "downloads", "body.bin")
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_responseBodyFile, testHttpMock.server.URL, 200, filepath.ToSlash(bodyFile)),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "" {
+						return fmt.Errorf(
+							`'body' output is %s; want ''`,
+							outputs["body"].Value,
+						)
+					}
+
+					contents, err := ioutil.ReadFile(bodyFile)
+					if err != nil {
+						return err
+					}
+
+					if !bytes.Equal(contents, testBinaryBody) {
+						return fmt.Errorf("response_body_file contents are %x; want %x", contents, testBinaryBody)
+					}
+
+					sum := sha256.Sum256(testBinaryBody)
+					if outputs["body_sha256"].Value != hex.EncodeToString(sum[:]) {
+						return fmt.Errorf(
+							`'body_sha256' output is %s; want '%x'`,
+							outputs["body_sha256"].Value,
+							sum,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_fromCache = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+}
+
+output "from_cache" {
+  value = data.http.http_test.from_cache
+}
+`
+
+func TestDataSource_fromCache(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_fromCache, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["from_cache"].Value != "false" {
+						return fmt.Errorf(
+							`'from_cache' output is %s; want 'false'`,
+							outputs["from_cache"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_maxResponseBodyBytes = `
+data "http" "http_test" {
+  url = "%s/large/meta_%d.txt"
+
+  max_response_body_bytes = %d
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_maxResponseBodyBytes(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_maxResponseBodyBytes, testHttpMock.server.URL, 200, 1024),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if len(outputs["body"].Value.(string)) != 1024 {
+						return fmt.Errorf(
+							`'body' output length is %d; want 1024`,
+							len(outputs["body"].Value.(string)),
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_maxResponseBodyBytesExceeded(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_maxResponseBodyBytes, testHttpMock.server.URL, 200, 100),
+				ExpectError: regexp.MustCompile("Response body exceeds the max_response_body_bytes limit of 100 bytes"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_binary = `
+data "http" "http_test" {
+  url = "%s/binary/meta_%d.txt"
+}
+
+output "body_base64" {
+  value = data.http.http_test.body_base64
+}
+`
+
+func TestDataSource_bodyBase64(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_binary, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					decoded, err := base64.StdEncoding.DecodeString(outputs["body_base64"].Value.(string))
+					if err != nil {
+						return fmt.Errorf("error decoding 'body_base64' output: %s", err)
+					}
+
+					if !bytes.Equal(decoded, testBinaryBody) {
+						return fmt.Errorf(
+							`decoded 'body_base64' output is %v; want %v`,
+							decoded,
+							testBinaryBody,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_waitFor = `
+data "http" "http_test" {
+  url = "%s/wait/meta_%d.txt"
+
+  wait_for {
+    status_code = 200
+    body_regex  = "^ready$"
+    timeout_ms  = 5000
+    interval_ms = 10
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_waitFor(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_waitFor, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "ready" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'ready'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_waitForTimeout = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  wait_for {
+    status_code = 200
+    timeout_ms  = 50
+    interval_ms = 10
+  }
+}
+`
+
+func TestDataSource_waitForTimeout(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_waitForTimeout, testHttpMock.server.URL, 404),
+				ExpectError: regexp.MustCompile("timed out after 50ms waiting for wait_for condition, last status\\s+code: 404"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_sse = `
+data "http" "http_test" {
+  url = "%s/sse/meta_%d.txt"
+
+  sse {
+    terminate_on_event = "done"
+    timeout_ms         = 5000
+  }
+}
+
+output "sse_events" {
+  value = jsonencode(data.http.http_test.sse_events)
+}
+`
+
+func TestDataSource_sse(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_sse, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					// The stream stays open after the terminating event, so
+					// reading it must stop there rather than at the timeout.
+					expected := `[{"data":"one","event":"message","id":"1"},{"data":"{\"progress\":50}\n{\"progress\":100}","event":"update","id":"2"},{"data":"","event":"done","id":"2"}]`
+					if outputs["sse_events"].Value != expected {
+						return fmt.Errorf(
+							`'sse_events' output is %s; want '%s'`,
+							outputs["sse_events"].Value,
+							expected,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_responseTrailers = `
+data "http" "http_test" {
+  url = "%s/trailer/meta_%d.txt"
+}
+
+output "response_trailers" {
+  value = jsonencode(data.http.http_test.response_trailers)
+}
+`
+
+func TestDataSource_responseTrailers(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_responseTrailers, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					var trailers map[string]string
+					if err := json.Unmarshal([]byte(outputs["response_trailers"].Value.(string)), &trailers); err != nil {
+						return err
+					}
+
+					expected := map[string]string{
+						"X-Checksum": "abc",
+						"X-Status":   "done",
+					}
+					if !reflect.DeepEqual(trailers, expected) {
+						return fmt.Errorf("'response_trailers' output is %v; want %v", trailers, expected)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_debug = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  bearer_token = "mytoken"
+  debug        = true
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "request_dump" {
+  value = data.http.http_test.request_dump
+}
+
+output "response_dump" {
+  value = data.http.http_test.response_dump
+}
+`
+
+func TestDataSource_debug(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_debug, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					requestDump := outputs["request_dump"].Value.(string)
+					for _, want := range []string{"GET /meta_200.txt HTTP/1.1\r\n", "Authorization: REDACTED\r\n"} {
+						if !strings.Contains(requestDump, want) {
+							return fmt.Errorf("'request_dump' output is %q; want it to contain %q", requestDump, want)
+						}
+					}
+
+					responseDump := outputs["response_dump"].Value.(string)
+					for _, want := range []string{"HTTP/1.1 200 OK\r\n", "\r\n\r\n1.0.0,GET"} {
+						if !strings.Contains(responseDump, want) {
+							return fmt.Errorf("'response_dump' output is %q; want it to contain %q", responseDump, want)
+						}
+					}
+
+					// The body is still read after dumping it.
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_cookieRedirect = `
+data "http" "http_test" {
+  url = "%s/cookie/login/meta_%d.txt"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_cookieRedirect(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_cookieRedirect, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_cookies = `
+data "http" "http_test" {
+  url = "%s/cookie/meta_%d.txt"
+
+  cookies = {
+    session = "%s"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_cookies(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_cookies, testHttpMock.server.URL, 200, "abc123"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_cookies, testHttpMock.server.URL, 200, "wrong"),
+				ExpectError: regexp.MustCompile("HTTP request error. Response code: 401"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_userAgentDefault = `
+data "http" "http_test" {
+  url = "%s/user-agent/meta_%d.txt"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+const testDataSourceConfig_userAgent = `
+data "http" "http_test" {
+  url = "%s/user-agent/meta_%d.txt"
+
+  user_agent        = "my-tool/1.0"
+  user_agent_append = %t
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_userAgent(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		config    string
+		userAgent string
+	}{
+		"default": {
+			config:    fmt.Sprintf(testDataSourceConfig_userAgentDefault, testHttpMock.server.URL, 200),
+			userAgent: "terraform-provider-http/dev",
+		},
+		"custom": {
+			config:    fmt.Sprintf(testDataSourceConfig_userAgent, testHttpMock.server.URL, 200, false),
+			userAgent: "my-tool/1.0",
+		},
+		"append": {
+			config:    fmt.Sprintf(testDataSourceConfig_userAgent, testHttpMock.server.URL, 200, true),
+			userAgent: "my-tool/1.0 terraform-provider-http/dev",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: tc.config,
+						Check: func(s *terraform.State) error {
+							_, ok := s.RootModule().Resources["data.http.http_test"]
+							if !ok {
+								return fmt.Errorf("missing data resource")
+							}
+
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.userAgent {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.userAgent,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_hostOverride = `
+data "http" "http_test" {
+  url = "%s/host/meta_%d.txt"
+
+  host_override = "example.com"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_hostOverride(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_hostOverride, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "example.com" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'example.com'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_bodySHA256 = `
+data "http" "http_test" {
+  url = "%s/binary/meta_%d.txt"
+}
+
+output "body_sha256" {
+  value = data.http.http_test.body_sha256
+}
+`
+
+func TestDataSource_bodySHA256(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_bodySHA256, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					// The checksum of testBinaryBody, which is not valid UTF-8.
+					expected := "e7961dd5d17f76b95afd79597ffa447d06495cf7803f10ec83ac6abbd82ed935"
+					if outputs["body_sha256"].Value != expected {
+						return fmt.Errorf(
+							`'body_sha256' output is %s; want '%s'`,
+							outputs["body_sha256"].Value,
+							expected,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_headBytes = `
+data "http" "http_test" {
+  url = "%s/stream/meta_%d.txt"
+
+  head_bytes = 15
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_headBytes(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_headBytes, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "012345678901234" {
+						return fmt.Errorf(
+							`'body' output is %s; want '012345678901234'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_redirect = `
+data "http" "http_test" {
+  url = "%s/redirect/meta_%d.txt"
+
+  follow_redirects = %t
+  expected_status_codes = [200, 302]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "response_headers" {
+  value = data.http.http_test.response_headers
+}
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+
+output "final_url" {
+  value = data.http.http_test.final_url
+}
+`
+
+func TestDataSource_followRedirects(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_redirect, testHttpMock.server.URL, 200, true),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["status_code"].Value != "200" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '200'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					finalURL := testHttpMock.server.URL + "/meta_200.txt"
+					if outputs["final_url"].Value != finalURL {
+						return fmt.Errorf(
+							`'final_url' output is %s; want '%s'`,
+							outputs["final_url"].Value,
+							finalURL,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_followRedirectsDisabled(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_redirect, testHttpMock.server.URL, 200, false),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["status_code"].Value != "302" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '302'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					response_headers := outputs["response_headers"].Value.(map[string]interface{})
+
+					if response_headers["Location"] != "/meta_200.txt" {
+						return fmt.Errorf(
+							`'Location' response header is %s; want '/meta_200.txt'`,
+							response_headers["Location"],
+						)
+					}
+
+					finalURL := testHttpMock.server.URL + "/redirect/meta_200.txt"
+					if outputs["final_url"].Value != finalURL {
+						return fmt.Errorf(
+							`'final_url' output is %s; want '%s'`,
+							outputs["final_url"].Value,
+							finalURL,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_ifModifiedSince = `
+data "http" "http_test" {
+  url = "%s/last-modified/meta_%d.txt"
+
+  if_modified_since = %q
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+`
+
+func TestDataSource_ifModifiedSince(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		ifModifiedSince string
+		statusCode      string
+		body            string
+	}{
+		"modified":              {ifModifiedSince: "2019-12-31T00:00:00Z", statusCode: "200", body: "1.0.0"},
+		"not modified":          {ifModifiedSince: "Wed, 01 Jan 2020 00:00:00 GMT", statusCode: "304", body: ""},
+		"not modified rfc 3339": {ifModifiedSince: "2020-01-01T02:00:00+01:00", statusCode: "304", body: ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_ifModifiedSince, testHttpMock.server.URL, 200, tc.ifModifiedSince),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["status_code"].Value != tc.statusCode {
+								return fmt.Errorf(
+									`'status_code' output is %s; want '%s'`,
+									outputs["status_code"].Value,
+									tc.statusCode,
+								)
+							}
+
+							if outputs["body"].Value != tc.body {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.body,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_ifNoneMatch = `
+data "http" "http_test" {
+  url = "%s/etag/meta_%d.txt"
+
+  if_none_match = %q
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+
+output "etag" {
+  value = data.http.http_test.etag
+}
+`
+
+func TestDataSource_ifNoneMatch(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		ifNoneMatch string
+		statusCode  string
+		body        string
+	}{
+		"modified":     {ifNoneMatch: `"v0"`, statusCode: "200", body: "1.0.0"},
+		"not modified": {ifNoneMatch: `"v1"`, statusCode: "304", body: ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_ifNoneMatch, testHttpMock.server.URL, 200, tc.ifNoneMatch),
+						Check: func(s *terraform.State) error {
+							_, ok := s.RootModule().Resources["data.http.http_test"]
+							if !ok {
+								return fmt.Errorf("missing data resource")
+							}
+
+							outputs := s.RootModule().Outputs
+
+							if outputs["status_code"].Value != tc.statusCode {
+								return fmt.Errorf(
+									`'status_code' output is %s; want '%s'`,
+									outputs["status_code"].Value,
+									tc.statusCode,
+								)
+							}
+
+							if outputs["body"].Value != tc.body {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.body,
+								)
+							}
+
+							if outputs["etag"].Value != `"v1"` {
+								return fmt.Errorf(
+									`'etag' output is %s; want '"v1"'`,
+									outputs["etag"].Value,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+const testDataSourceConfig_skipIf = `
+data "http" "http_test" {
+  url = "%s/meta_200.txt"
+
+  skip_if   = true
+  skip_body = "skipped"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+
+output "status_code" {
+  value = data.http.http_test.status_code
+}
+`
+
+func TestDataSource_skipIf(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("1.0.0"))
+	}))
+
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_skipIf, server.URL),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "skipped" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'skipped'`,
+							outputs["body"].Value,
+						)
+					}
+
+					if outputs["status_code"].Value != "0" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '0'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					if n := atomic.LoadInt32(&requests); n != 0 {
+						return fmt.Errorf("server received %d requests; want 0", n)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_proxy = `
+data "http" "http_test" {
+  url = "http://terraform-provider-http.invalid/meta_%d.txt"
+
+  proxy_url = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(fmt.Sprintf("proxied,%s,%s", r.Method, r.URL)))
+	}))
+
+	defer proxy.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_proxy, 200, proxy.URL),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					want := "proxied,GET,http://terraform-provider-http.invalid/meta_200.txt"
+					if outputs["body"].Value != want {
+						return fmt.Errorf(
+							`'body' output is %s; want '%s'`,
+							outputs["body"].Value,
+							want,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_noProxy = `
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  proxy_url = "%s"
+  no_proxy  = ["%s"]
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_noProxy(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("proxied"))
+	}))
+
+	defer proxy.Close()
+
+	cases := map[string]struct {
+		NoProxy  string
+		Expected string
+	}{
+		"cidr": {
+			NoProxy:  "127.0.0.0/8",
+			Expected: "1.0.0,GET",
+		},
+		"ip": {
+			NoProxy:  "127.0.0.1",
+			Expected: "1.0.0,GET",
+		},
+		"other host": {
+			NoProxy:  "example.com",
+			Expected: "proxied",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				Providers: testProviders,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(testDataSourceConfig_noProxy, testHttpMock.server.URL, 200, proxy.URL, tc.NoProxy),
+						Check: func(s *terraform.State) error {
+							outputs := s.RootModule().Outputs
+
+							if outputs["body"].Value != tc.Expected {
+								return fmt.Errorf(
+									`'body' output is %s; want '%s'`,
+									outputs["body"].Value,
+									tc.Expected,
+								)
+							}
+
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDataSource_proxyInvalidScheme(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_proxy, 200, "ftp://127.0.0.1:21"),
+				ExpectError: regexp.MustCompile(`Error parsing proxy_url: unsupported scheme "ftp"`),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_resolve = `
+data "http" "http_test" {
+  url = "http://terraform-provider-http.test:%[1]s/host/meta_%[2]d.txt"
+
+  resolve = {
+    "terraform-provider-http.test:%[1]s" = "127.0.0.1"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_resolve(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	_, port, err := net.SplitHostPort(testHttpMock.server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_resolve, port, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					// The request reaches the server with the original host.
+					host := "terraform-provider-http.test:" + port
+					if outputs["body"].Value != host {
+						return fmt.Errorf(
+							`'body' output is %s; want '%s'`,
+							outputs["body"].Value,
+							host,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_unixSocket = `
+data "http" "http_test" {
+  url = "http://localhost/meta_%d.txt"
+
+  unix_socket = "%s"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_unixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	socketPath := filepath.Join(dir, "http.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(newMockHttpHandler())
+	server.Listener = listener
+	server.Start()
+
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_unixSocket, 200, filepath.ToSlash(socketPath)),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_withHeaders = `
+data "http" "http_test" {
+  url = "%s/restricted/meta_%d.txt"
+
+  request_headers = {
+    "Authorization" = "Zm9vOmJhcg=="
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_withHeaders200(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_withHeaders, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_basicAuth = `
+data "http" "http_test" {
+  url = "%s/restricted/meta_%d.txt"
+
+  basic_auth {
+    username = "foo"
+    password = "%s"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_basicAuth200(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_basicAuth, testHttpMock.server.URL, 200, "bar"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_basicAuth403(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_basicAuth, testHttpMock.server.URL, 200, "wrong"),
+				ExpectError: regexp.MustCompile("HTTP request error. Response code: 403"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_basicAuthConflict = `
+data "http" "http_test" {
+  url = "%s/restricted/meta_%d.txt"
+
+  request_headers = {
+    "authorization" = "Zm9vOmJhcg=="
+  }
+
+  basic_auth {
+    username = "foo"
+    password = "bar"
+  }
+}
+`
+
+func TestDataSource_basicAuthConflict(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_basicAuthConflict, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("basic_auth conflicts with the Authorization request header"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_bearerToken = `
+data "http" "http_test" {
+  url = "%s/authorization/meta_%d.txt"
+
+  bearer_token = "mytoken"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_bearerToken(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_bearerToken, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "Bearer mytoken" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'Bearer mytoken'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_bearerTokenConflict = `
+data "http" "http_test" {
+  url = "%s/authorization/meta_%d.txt"
+
+  request_headers = {
+    "Authorization" = "Bearer other"
+  }
+
+  bearer_token = "mytoken"
+}
+`
+
+func TestDataSource_bearerTokenConflict(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_bearerTokenConflict, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("bearer_token conflicts with the Authorization request header"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_awsSigV4 = `
+data "http" "http_test" {
+  url = "%s/sigv4/meta_%d.txt"
+
+  aws_sigv4 {
+    region     = "us-east-1"
+    service    = "execute-api"
+    access_key = "AKID"
+    secret_key = "SECRET"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_awsSigV4(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_awsSigV4, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_awsSigV4Conflict = `
+data "http" "http_test" {
+  url = "%s/sigv4/meta_%d.txt"
+
+  request_headers = {
+    "Authorization" = "Bearer other"
+  }
+
+  aws_sigv4 {
+    region     = "us-east-1"
+    service    = "execute-api"
+    access_key = "AKID"
+    secret_key = "SECRET"
+  }
+}
+`
+
+func TestDataSource_awsSigV4Conflict(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_awsSigV4Conflict, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("aws_sigv4 conflicts with the Authorization request header"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_gcpIDToken = `
+data "http" "http_test" {
+  url = "%s/authorization/meta_%d.txt"
+
+  gcp_id_token {
+    audience = "https://example.run.app"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+// testGCPIDTokenSource issues tokens naming the audience they are for.
+type testGCPIDTokenSource struct {
+	audience string
+}
+
+func (s testGCPIDTokenSource) Token() (*oauth2.Token, error) {
+	return &oauth2.Token{AccessToken: "token-for-" + s.audience}, nil
+}
+
+func TestDataSource_gcpIDToken(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	defaultGCPIDTokenSource := newGCPIDTokenSource
+	newGCPIDTokenSource = func(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+		return testGCPIDTokenSource{audience: audience}, nil
+	}
+	defer func() {
+		newGCPIDTokenSource = defaultGCPIDTokenSource
+	}()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_gcpIDToken, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "Bearer token-for-https://example.run.app" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'Bearer token-for-https://example.run.app'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_gcpIDTokenCredentialsError(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	defaultGCPIDTokenSource := newGCPIDTokenSource
+	newGCPIDTokenSource = func(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+		return nil, fmt.Errorf("could not find default credentials")
+	}
+	defer func() {
+		newGCPIDTokenSource = defaultGCPIDTokenSource
+	}()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_gcpIDToken, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("Error loading GCP credentials: could not find default credentials"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_ntlmAuth = `
+data "http" "http_test" {
+  url = "%s/ntlm/meta_%d.txt"
+
+  ntlm_auth {
+    username = "User"
+    password = "%s"
+    domain   = "Domain"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_ntlmAuth(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_ntlmAuth, testHttpMock.server.URL, 200, "Password"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_ntlmAuthWrongPassword(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_ntlmAuth, testHttpMock.server.URL, 200, "wrong"),
+				ExpectError: regexp.MustCompile("Response code: 401"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_digestAuth = `
+data "http" "http_test" {
+  url = "%s/digest/meta_%d.txt"
+
+  digest_auth {
+    username = "User"
+    password = "%s"
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_digestAuth(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_digestAuth, testHttpMock.server.URL, 200, "Password"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_digestAuthWrongPassword(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_digestAuth, testHttpMock.server.URL, 200, "wrong"),
+				ExpectError: regexp.MustCompile("Response code: 401"),
+			},
+		},
+	})
+}
+
+func TestDataSource_kerberosAuth(t *testing.T) {
+	server, ccacheFile := newKerberosTestServer(t)
+	defer server.Close()
+	defer os.Remove(ccacheFile)
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+		"url": server.URL,
+		"kerberos_auth": []interface{}{map[string]interface{}{
+			"ccache_file": ccacheFile,
+			"krb5_conf":   testKrb5Conf,
+		}},
+	})
+
+	if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	if body := d.Get("body").(string); body != testKerberosUser {
+		t.Fatalf("expected body %q, got %q", testKerberosUser, body)
+	}
+}
+
+const testDataSourceConfig_hmacSignature = `
+data "http" "http_test" {
+  url            = "%s/hmac/meta_%d.txt"
+  request_method = "POST"
+  request_body   = "payload"
+
+  hmac_signature {
+    secret      = "secret"
+    header_name = "X-Signature"
+    prefix      = "sha256="
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_hmacSignature(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_hmacSignature, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_hmacSignatureConflict = `
+data "http" "http_test" {
+  url = "%s/hmac/meta_%d.txt"
+
+  request_headers = {
+    "x-signature" = "other"
+  }
+
+  hmac_signature {
+    secret      = "secret"
+    header_name = "X-Signature"
+  }
+}
+`
+
+func TestDataSource_hmacSignatureConflict(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_hmacSignatureConflict, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile("hmac_signature conflicts with the X-Signature request header"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_oauth2ClientCredentials = `
+data "http" "http_test" {
+  url = "%[1]s/authorization/meta_%[2]d.txt"
+
+  oauth2_client_credentials {
+    token_url     = "%[1]s/oauth2/token"
+    client_id     = "client"
+    client_secret = "%[3]s"
+    scopes        = ["read", "write"]
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_oauth2ClientCredentials(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_oauth2ClientCredentials, testHttpMock.server.URL, 200, "secret"),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "Bearer read-write-token" {
+						return fmt.Errorf(
+							`'body' output is %s; want 'Bearer read-write-token'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_oauth2ClientCredentialsTokenError(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testDataSourceConfig_oauth2ClientCredentials, testHttpMock.server.URL, 200, "wrong"),
+				ExpectError: regexp.MustCompile("Error obtaining OAuth2 token: token endpoint returned 401: invalid_client"),
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_requestHeadersList = `
+data "http" "http_test" {
+  url = "%s/repeat/meta_%d.txt"
+
+  request_headers = {
+    "X-Repeat" = "0"
+  }
+
+  request_headers_list {
+    name   = "x-repeat"
+    values = ["1", "2"]
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestDataSource_requestHeadersList(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_requestHeadersList, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "0|1|2" {
+						return fmt.Errorf(
+							`'body' output is %s; want '0|1|2'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_utf8 = `
+data "http" "http_test" {
+  url = "%s/utf-8/meta_%d.txt"
+}
+
+output "body" {
+  value = "${data.http.http_test.body}"
+}
+`
+
+func TestDataSource_utf8(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_utf8, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_contentType = `
+data "http" "http_test" {
+  url = "%s/utf-8/meta_%d.txt"
+}
+
+output "content_type" {
+  value = data.http.http_test.content_type
+}
+
+output "content_type_mime" {
+  value = data.http.http_test.content_type_mime
+}
+`
+
+func TestDataSource_contentType(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_contentType, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["content_type"].Value != "text/plain; charset=UTF-8" {
+						return fmt.Errorf(
+							`'content_type' output is %s; want 'text/plain; charset=UTF-8'`,
+							outputs["content_type"].Value,
+						)
+					}
+
+					if outputs["content_type_mime"].Value != "text/plain" {
+						return fmt.Errorf(
+							`'content_type_mime' output is %s; want 'text/plain'`,
+							outputs["content_type_mime"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testDataSourceConfig_utf16 = `
+data "http" "http_test" {
+  url = "%s/utf-16/meta_%d.txt"
+}
+
+output "body" {
+  value = "${data.http.http_test.body}"
+}
+
+output "charset" {
+  value = data.http.http_test.charset
+}
+
+output "response_body_json" {
+  value = data.http.http_test.response_body_json
+}
+`
+
+func TestDataSource_utf16(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testDataSourceConfig_utf16, testHttpMock.server.URL, 200),
+				// This should now be a warning, but unsure how to test for it...
+				//ExpectWarning: regexp.MustCompile("Content-Type is not a text type. Got: application/json; charset=UTF-16"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					// The body is sent as UTF-16LE and converted to UTF-8.
+					expected := map[string]string{
+						"body":               `"1.0.0"`,
+						"charset":            "utf-16le",
+						"response_body_json": `"1.0.0"`,
+					}
+					for name, want := range expected {
+						if outputs[name].Value != want {
+							return fmt.Errorf(
+								`'%s' output is %s; want '%s'`,
+								name,
+								outputs[name].Value,
+								want,
+							)
+						}
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestDataSource_skipContentTypeWarning(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	cases := map[string]struct {
+		Skip          bool
+		ExpectWarning bool
+	}{
+		"default": {
+			Skip:          false,
+			ExpectWarning: true,
+		},
+		"skipped": {
+			Skip:          true,
+			ExpectWarning: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// The test framework does not report warnings, so the data source
+			// is read directly.
+			p := New()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+				t.Fatalf("unexpected error configuring provider: %v", diags)
+			}
+
+			d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+				"url":                       fmt.Sprintf("%s/utf-16/meta_200.txt", testHttpMock.server.URL),
+				"skip_content_type_warning": tc.Skip,
+			})
+
+			diags := dataSourceRead(context.Background(), d, p.Meta())
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			warned := false
+			for _, diag := range diags {
+				if strings.HasPrefix(diag.Summary, "Content-Type is not recognized as a text type") {
+					warned = true
+				}
+			}
+
+			if warned != tc.ExpectWarning {
+				t.Fatalf("expected Content-Type warning %t, got %t", tc.ExpectWarning, warned)
+			}
+		})
+	}
+}
+
+func setUpMockHttpServer() *TestHttpMock {
+	Server := httptest.NewServer(newMockHttpHandler())
+
+	return &TestHttpMock{
+		server: Server,
+	}
+}
+
+// setUpMockHttpsServer starts a TLS server with the given configuration. When
+// no certificate is configured the server uses a self-signed certificate.
+func setUpMockHttpsServer(tlsConfig *tls.Config) *TestHttpMock {
+	Server := httptest.NewUnstartedServer(newMockHttpHandler())
+	Server.TLS = tlsConfig
+	Server.StartTLS()
+
+	return &TestHttpMock{
+		server: Server,
+	}
+}
+
+func setUpMockHttp2Server() *TestHttpMock {
+	Server := httptest.NewUnstartedServer(newMockHttpHandler())
+	Server.EnableHTTP2 = true
+	Server.StartTLS()
+
+	return &TestHttpMock{
+		server: Server,
+	}
+}
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+// newTestCertificate creates a certificate from template signed by parent, or
+// self-signed when parent is nil.
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+// testBinaryBody is not valid UTF-8.
+var testSigV4Authorization = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=AKID/\d{8}/us-east-1/execute-api/aws4_request, SignedHeaders=[a-z0-9;-]+, Signature=[0-9a-f]{64}$`)
+
+const testNestedJSONBody = `{
+  "service": {
+    "name": "api",
+    "ports": [8080, 8443],
+    "endpoints": [
+      {"host": "api-1.example.com"},
+      {"host": "api-2.example.com"}
+    ],
+    "tags": ["a", "b"]
+  }
+}`
+
+var testBinaryBody = []byte{0xff, 0xfe, 0x00, 0x80, 0x31}
+
+func newMockHttpHandler() http.Handler {
+	var retryRequests int
+	var conflictRequests int
+	var waitRequests int
+	var retryAfterRequests int
+	var connectionRequests int
+	connections := make(map[string]bool)
+	// Requests for /rate and /count may be sent concurrently.
+	var rateMu sync.Mutex
+	var rateStart time.Time
+	var countMu sync.Mutex
+	var countRequests int
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Add("X-Single", "foobar")
+		w.Header().Add("X-Double", "1")
+		w.Header().Add("X-Double", "2")
+		if r.URL.Path == "/meta_200.txt" {
+			var body bytes.Buffer
+			body.WriteString("1.0.0")
+			if r.Method == "GET" {
+				body.WriteString(",GET")
+			} else if r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH" {
+				buf := new(bytes.Buffer)
+				buf.ReadFrom(r.Body)
+				newStr := buf.String()
+				body.WriteString(fmt.Sprintf(",%s,%s", r.Method, newStr))
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write(body.Bytes())
+		} else if r.URL.Path == "/set-cookie/meta_200.txt" {
+			w.Header().Add("Set-Cookie", "session=abc; Path=/; HttpOnly")
+			w.Header().Add("Set-Cookie", "theme=dark, light; Path=/")
+			w.WriteHeader(http.StatusOK)
+		} else if r.URL.Path == "/sse/meta_200.txt" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(": connected\n\nid: 1\ndata: one\n\n"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte("id: 2\nevent: update\ndata: {\"progress\":50}\ndata: {\"progress\":100}\n\n"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte("event: done\n\n"))
+			w.(http.Flusher).Flush()
+			// The stream is left open until the client goes away.
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+		} else if r.URL.Path == "/remote-addr/meta_200.txt" {
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(host))
+		} else if r.URL.Path == "/version/meta_200.txt" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("version=1.2.3"))
+		} else if strings.HasPrefix(r.URL.Path, "/path-segments/") {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.URL.RequestURI()))
+		} else if r.URL.Path == "/referer-origin/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Header.Get("Referer") + "," + r.Header.Get("Origin")))
+		} else if r.URL.Path == "/request-id/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Header.Get("X-Request-ID")))
+		} else if r.URL.Path == "/content-type/meta_200.txt" {
+			body, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Header.Get("Content-Type") + "," + string(body)))
+		} else if r.URL.Path == "/trailer/meta_200.txt" {
+			// X-Unsent is announced but never sent.
+			w.Header().Set("Trailer", "X-Checksum, X-Unsent")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+			w.Header().Set("X-Checksum", "abc")
+			w.Header().Set(http.TrailerPrefix+"X-Status", "done")
+		} else if r.URL.Path == "/binary/meta_200.txt" {
+			body, _ := ioutil.ReadAll(r.Body)
+			if !bytes.Equal(body, []byte{0x00, 0xff, 0x10, 0x80}) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(hex.EncodeToString(body)))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(hex.EncodeToString(body)))
+		} else if r.URL.Path == "/upload/meta_200.txt" {
+			n, _ := io.Copy(ioutil.Discard, r.Body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(fmt.Sprintf("%d,%d", n, r.ContentLength)))
+		} else if r.URL.Path == "/body/meta_200.txt" {
+			body, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Method + "," + string(body)))
+		} else if r.URL.Path == "/urlencoded/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("hello%20world"))
+		} else if r.URL.Path == "/urlencoded/invalid/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("100%"))
+		} else if r.URL.Path == "/paginate/meta_200.txt" {
+			// Three pages, linked first by a relative and then by an absolute
+			// URL.
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("page") {
+			case "":
+				w.Header().Add("Link", `<meta_200.txt?page=2>; rel="next"`)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`[1, 2]`))
+			case "2":
+				w.Header().Add("Link", fmt.Sprintf(`<http://%[1]s/paginate/meta_200.txt>; rel="first", <http://%[1]s/paginate/meta_200.txt?page=3>; rel="next"`, r.Host))
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`[3]`))
+			case "3":
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`[{"id": 4}]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		} else if r.URL.Path == "/query/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.URL.RawQuery))
+		} else if r.URL.Path == "/json/meta_200.txt" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"version": "1.0.0", "tags": [1, 2.5]}`))
+		} else if r.URL.Path == "/json/nested/meta_200.txt" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(testNestedJSONBody))
+		} else if r.URL.Path == "/xml/meta_200.txt" {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`<?xml version="1.0"?><release id="1"><version>1.0.0</version><tag>a</tag><tag>b</tag><price currency="EUR">10</price></release>`))
+		} else if r.URL.Path == "/accept/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Header.Get("Accept") + "," + r.Header.Get("Accept-Encoding")))
+		} else if r.URL.Path == "/gzip/meta_200.txt" {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("1.0.0"))
+				return
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			w.WriteHeader(http.StatusOK)
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte("1.0.0"))
+			gz.Close()
+		} else if r.URL.Path == "/empty/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+		} else if r.URL.Path == "/chunked/meta_200.txt" {
+			// Flushing before the handler returns sends the body with chunked
+			// encoding and no Content-Length.
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(",chunked"))
+		} else if r.URL.Path == "/large/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write(bytes.Repeat([]byte("a"), 1024))
+		} else if r.URL.Path == "/stream/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			// Stream chunks until the client goes away.
+			for i := 0; i < 10000; i++ {
+				if _, err := w.Write([]byte("0123456789")); err != nil {
+					return
+				}
+				w.(http.Flusher).Flush()
+			}
+		} else if r.URL.Path == "/binary/meta_200.txt" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write(testBinaryBody)
+		} else if r.URL.Path == "/redirect/meta_200.txt" {
+			http.Redirect(w, r, "/meta_200.txt", http.StatusFound)
+		} else if r.URL.Path == "/authorization/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Header.Get("Authorization")))
+		} else if r.URL.Path == "/oauth2/token" {
+			clientID, clientSecret, ok := r.BasicAuth()
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method != http.MethodPost || r.PostFormValue("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"unsupported_grant_type"}`))
+			} else if !ok || clientID != "client" || clientSecret != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_client"}`))
+			} else {
+				token := strings.Join(strings.Fields(r.PostFormValue("scope")), "-") + "-token"
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"access_token":"` + token + `","token_type":"bearer","expires_in":3600}`))
+			}
+		} else if r.URL.Path == "/sigv4/meta_200.txt" {
+			if testSigV4Authorization.MatchString(r.Header.Get("Authorization")) && r.Header.Get("X-Amz-Date") != "" {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("1.0.0"))
+			} else {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		} else if r.URL.Path == "/hmac/meta_200.txt" {
+			body, _ := ioutil.ReadAll(r.Body)
+			mac := hmac.New(sha256.New, []byte("secret"))
+			mac.Write(body)
+			if hmac.Equal([]byte(r.Header.Get("X-Signature")), []byte("sha256="+hex.EncodeToString(mac.Sum(nil)))) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("1.0.0"))
+			} else {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		} else if r.URL.Path == "/ntlm/meta_200.txt" {
+			// Performs the NTLM handshake, accepting User with password
+			// Password in Domain.
+			message, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(r.Header.Get("Authorization"), "NTLM "))
+			if err != nil || len(message) < 12 {
+				w.Header().Set("WWW-Authenticate", "NTLM")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			switch binary.LittleEndian.Uint32(message[8:]) {
+			case 1:
+				w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(testNTLMChallengeMessage(testNTLMTargetInfo)))
+				w.WriteHeader(http.StatusUnauthorized)
+			case 3:
+				field := func(offset int) []byte {
+					length := int(binary.LittleEndian.Uint16(message[offset:]))
+					start := int(binary.LittleEndian.Uint32(message[offset+4:]))
+					return message[start : start+length]
+				}
+				ntResponse := field(20)
+				proof := hmacMD5(ntowfv2("User", "Password", "Domain"), append(testNTLMServerChallenge[:], ntResponse[16:]...))
+				if bytes.Equal(field(36), utf16LE("User")) && hmac.Equal(proof, ntResponse[:16]) {
 					w.WriteHeader(http.StatusOK)
 					w.Write([]byte("1.0.0"))
 				} else {
-					w.WriteHeader(http.StatusForbidden)
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		} else if r.URL.Path == "/digest/meta_200.txt" {
+			// Answers a request without valid credentials for User with
+			// password Password with a digest challenge.
+			challenge := &digestChallenge{realm: "test", nonce: "testnonce", opaque: "testopaque", algorithm: "SHA-256", qop: "auth"}
+			params, err := parseDigestParams(strings.TrimPrefix(r.Header.Get("Authorization"), "Digest "))
+			if err == nil && params["username"] == "User" && params["uri"] == r.URL.RequestURI() && params["opaque"] == challenge.opaque &&
+				params["response"] == challenge.response(&digestAuth{username: "User", password: "Password"}, r.Method, params["uri"], nil, params["cnonce"]) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("1.0.0"))
+				return
+			}
+			w.Header().Set("WWW-Authenticate", `Digest realm="test", qop="auth", algorithm=SHA-256, nonce="testnonce", opaque="testopaque"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		} else if r.URL.Path == "/graphql/meta_200.txt" {
+			var req struct {
+				Query     string                 `json:"query"`
+				Variables map[string]interface{} `json:"variables"`
+			}
+			if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&req) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			if strings.Contains(req.Query, "unknownField") {
+				w.Write([]byte(`{"errors":[{"message":"Cannot query field \"unknownField\" on type \"Query\"."}]}`))
+				return
+			}
+			resp, _ := json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"method":    r.Method,
+					"query":     req.Query,
+					"variables": req.Variables,
+				},
+			})
+			w.Write(resp)
+		} else if r.URL.Path == "/multipart/meta_200.txt" {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			var fields []string
+			for name := range r.MultipartForm.Value {
+				fields = append(fields, name)
+			}
+			for name, files := range r.MultipartForm.File {
+				for _, file := range files {
+					fields = append(fields, fmt.Sprintf("%s:%d", name, file.Size))
 				}
-			} else if r.URL.Path == "/utf-8/meta_200.txt" {
-				w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+			}
+			sort.Strings(fields)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strings.Join(fields, ",")))
+		} else if r.URL.Path == "/etag/meta_200.txt" {
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+			} else {
+				w.Header().Set("Content-Type", "text/plain")
 				w.WriteHeader(http.StatusOK)
 				w.Write([]byte("1.0.0"))
-			} else if r.URL.Path == "/utf-16/meta_200.txt" {
-				w.Header().Set("Content-Type", "application/json; charset=UTF-16")
+			}
+		} else if r.URL.Path == "/last-modified/meta_200.txt" {
+			lastModified := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+			if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+				w.WriteHeader(http.StatusNotModified)
+			} else {
 				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("\"1.0.0\""))
-			} else if r.URL.Path == "/meta_404.txt" {
-				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("1.0.0"))
+			}
+		} else if r.URL.Path == "/cookie/login/meta_200.txt" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123", Path: "/"})
+			http.Redirect(w, r, "/cookie/meta_200.txt", http.StatusFound)
+		} else if r.URL.Path == "/cookie/meta_200.txt" {
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != "abc123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/user-agent/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.UserAgent()))
+		} else if r.URL.Path == "/host/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Host))
+		} else if r.URL.Path == "/proto/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(r.Proto))
+		} else if r.URL.Path == "/repeat/meta_200.txt" {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strings.Join(r.Header["X-Repeat"], "|")))
+		} else if r.URL.Path == "/restricted/meta_200.txt" {
+			username, password, ok := r.BasicAuth()
+			if r.Header.Get("Authorization") == "Zm9vOmJhcg==" || (ok && username == "foo" && password == "bar") {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("1.0.0"))
 			} else {
-				w.WriteHeader(http.StatusNotFound)
+				w.WriteHeader(http.StatusForbidden)
 			}
-		}),
-	)
-
-	return &TestHttpMock{
-		server: Server,
-	}
+		} else if r.URL.Path == "/utf-8/meta_200.txt" {
+			w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/utf-16/meta_200.txt" {
+			w.Header().Set("Content-Type", "application/json; charset=UTF-16")
+			w.WriteHeader(http.StatusOK)
+			w.Write(utf16LE("\"1.0.0\""))
+		} else if r.URL.Path == "/slow/meta_200.txt" {
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/retry/meta_200.txt" {
+			retryRequests++
+			if retryRequests <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/unavailable/meta_200.txt" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else if r.URL.Path == "/retry/conflict/meta_200.txt" {
+			conflictRequests++
+			if conflictRequests <= 2 {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/wait/meta_200.txt" {
+			waitRequests++
+			if waitRequests <= 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte("starting"))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("ready"))
+		} else if r.URL.Path == "/retry-after/meta_200.txt" {
+			retryAfterRequests++
+			if retryAfterRequests == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("1.0.0"))
+		} else if r.URL.Path == "/connections/meta_200.txt" {
+			// Fails the first request, then reports the number of distinct
+			// client connections seen.
+			connectionRequests++
+			connections[r.RemoteAddr] = true
+			if connectionRequests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strconv.Itoa(len(connections))))
+		} else if r.URL.Path == "/rate/meta_200.txt" {
+			// Reports the milliseconds elapsed since the first request.
+			rateMu.Lock()
+			if rateStart.IsZero() {
+				rateStart = time.Now()
+			}
+			elapsed := time.Since(rateStart)
+			rateMu.Unlock()
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strconv.FormatInt(elapsed.Milliseconds(), 10)))
+		} else if r.URL.Path == "/count/meta_200.txt" || r.URL.Path == "/count/no-store/meta_200.txt" {
+			// Reports the number of requests to either path so far.
+			countMu.Lock()
+			countRequests++
+			count := countRequests
+			countMu.Unlock()
+			if r.URL.Path == "/count/no-store/meta_200.txt" {
+				w.Header().Set("Cache-Control", "no-store")
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(strconv.Itoa(count)))
+		} else if r.URL.Path == "/meta_404.txt" {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
 }
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+)
+
+// redactedHeaders are the headers whose values are always replaced in
+// request_dump and response_dump, as they commonly carry credentials.
+var redactedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+}
+
+// dumpRequest returns the request as it is sent on the wire, including the
+// body, with the values of the redacted headers replaced. The request body
+// remains readable afterwards.
+func dumpRequest(req *http.Request, redact []string) (string, error) {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err != nil {
+		return "", err
+	}
+
+	return redactDump(dump, redact), nil
+}
+
+// dumpResponse returns the response as it was received, including the body
+// when body is true, with the values of the redacted headers replaced. The
+// response body remains readable afterwards.
+func dumpResponse(resp *http.Response, body bool, redact []string) (string, error) {
+	dump, err := httputil.DumpResponse(resp, body)
+	if err != nil {
+		return "", err
+	}
+
+	return redactDump(dump, redact), nil
+}
+
+// redactedHeaderNames returns the canonical names of the redacted headers and
+// of the headers named in redact.
+func redactedHeaderNames(redact []string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range append(redactedHeaders, redact...) {
+		names[textproto.CanonicalMIMEHeaderKey(name)] = true
+	}
+
+	return names
+}
+
+// redactDump replaces the values of the redacted headers, and of any header
+// named in redact, in the header section of a wire dump.
+func redactDump(dump []byte, redact []string) string {
+	names := redactedHeaderNames(redact)
+
+	header, body := dump, []byte(nil)
+	if i := bytes.Index(dump, []byte("\r\n\r\n")); i >= 0 {
+		header, body = dump[:i], dump[i:]
+	}
+
+	lines := strings.Split(string(header), "\r\n")
+	for n, line := range lines {
+		// The first line is the request or status line.
+		if n == 0 {
+			continue
+		}
+		if i := strings.IndexByte(line, ':'); i > 0 && names[textproto.CanonicalMIMEHeaderKey(line[:i])] {
+			lines[n] = line[:i] + ": REDACTED"
+		}
+	}
+
+	return strings.Join(lines, "\r\n") + string(body)
+}
+
+// flattenRedactedHeaders converts headers to a map of strings as
+// flattenResponseHeaders does, replacing the values of the redacted headers
+// and of any header named in redact.
+func flattenRedactedHeaders(header http.Header, redact []string) map[string]string {
+	names := redactedHeaderNames(redact)
+
+	flattened := flattenResponseHeaders(header)
+	for name := range flattened {
+		if names[textproto.CanonicalMIMEHeaderKey(name)] {
+			flattened[name] = "REDACTED"
+		}
+	}
+
+	return flattened
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRedactDump(t *testing.T) {
+	cases := map[string]struct {
+		Dump     string
+		Redact   []string
+		Expected string
+	}{
+		"default headers": {
+			Dump:     "GET / HTTP/1.1\r\nHost: example.com\r\nAuthorization: Bearer secret\r\ncookie: a=b\r\n\r\n",
+			Expected: "GET / HTTP/1.1\r\nHost: example.com\r\nAuthorization: REDACTED\r\ncookie: REDACTED\r\n\r\n",
+		},
+		"additional headers": {
+			Dump:     "HTTP/1.1 200 OK\r\nX-Api-Key: secret\r\nX-Other: value\r\n\r\nbody",
+			Redact:   []string{"x-api-key"},
+			Expected: "HTTP/1.1 200 OK\r\nX-Api-Key: REDACTED\r\nX-Other: value\r\n\r\nbody",
+		},
+		"body unchanged": {
+			Dump:     "POST / HTTP/1.1\r\nHost: example.com\r\n\r\nAuthorization: not a header\r\n",
+			Expected: "POST / HTTP/1.1\r\nHost: example.com\r\n\r\nAuthorization: not a header\r\n",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := redactDump([]byte(tc.Dump), tc.Redact); actual != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestFlattenRedactedHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"Bearer secret"},
+		"X-Api-Key":     {"secret"},
+		"X-Double":      {"1", "2"},
+	}
+
+	expected := map[string]string{
+		"Authorization": "REDACTED",
+		"X-Api-Key":     "REDACTED",
+		"X-Double":      "1, 2",
+	}
+
+	if actual := flattenRedactedHeaders(header, []string{"x-api-key"}); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Digest access authentication is described in RFC 7616.
+
+// digestHashes are the supported digest algorithms, without the -sess suffix.
+var digestHashes = map[string]func() hash.Hash{
+	"MD5":         md5.New,
+	"SHA-256":     sha256.New,
+	"SHA-512-256": sha512.New512_256,
+}
+
+// digestNonceCount is the nc value sent with every request, as each answers a
+// fresh challenge.
+const digestNonceCount = "00000001"
+
+// digestAuth holds the credentials of the digest_auth block.
+type digestAuth struct {
+	username string
+	password string
+}
+
+func expandDigestAuth(v []interface{}) *digestAuth {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return &digestAuth{
+		username: m["username"].(string),
+		password: m["password"].(string),
+	}
+}
+
+// applyDigestAuth wraps the client's transport to perform digest
+// authentication from the digest_auth block, if configured.
+func applyDigestAuth(client *http.Client, d *schema.ResourceData) error {
+	auth := expandDigestAuth(d.Get("digest_auth").([]interface{}))
+	if auth == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("digest_auth conflicts with the Authorization request header")
+	}
+
+	client.Transport = &digestTransport{base: client.Transport, auth: auth}
+
+	return nil
+}
+
+// digestTransport answers a 401 response with a Digest challenge by resending
+// the request with credentials computed from the challenge.
+type digestTransport struct {
+	base http.RoundTripper
+	auth *digestAuth
+}
+
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge, err := digestChallengeHeader(resp.Header)
+	if err != nil {
+		discardBody(resp)
+		return nil, fmt.Errorf("Error parsing digest challenge: %s", err)
+	}
+	if challenge == nil {
+		return resp, nil
+	}
+	discardBody(resp)
+
+	// Only auth-int protection covers the body.
+	var body []byte
+	if challenge.qop == "auth-int" && req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		body, err = ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cnonce [16]byte
+	if _, err := rand.Read(cnonce[:]); err != nil {
+		return nil, err
+	}
+
+	authorization := challenge.authorization(t.auth, req.Method, req.URL.RequestURI(), body, hex.EncodeToString(cnonce[:]))
+	authReq, err := withAuthorization(req, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(authReq)
+}
+
+// digestChallenge holds the parameters of a Digest challenge used to answer
+// it. qop is the chosen quality of protection, empty if the server offered
+// none.
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	userhash  bool
+}
+
+// digestChallengeHeader returns the first Digest challenge in the
+// WWW-Authenticate headers that can be answered, or nil if none is offered.
+// Servers list their challenges in order of preference.
+func digestChallengeHeader(header http.Header) (*digestChallenge, error) {
+	var lastErr error
+	for _, v := range header.Values("WWW-Authenticate") {
+		fields := strings.SplitN(strings.TrimSpace(v), " ", 2)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], "Digest") {
+			continue
+		}
+
+		params, err := parseDigestParams(fields[1])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		challenge, err := newDigestChallenge(params)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		return challenge, nil
+	}
+
+	return nil, lastErr
+}
+
+// parseDigestParams parses the comma separated auth-params of a Digest
+// challenge or credentials. Quoted values are unquoted and names are lower
+// cased.
+func parseDigestParams(s string) (map[string]string, error) {
+	params := make(map[string]string)
+
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params, nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("parameter %q has no value", s)
+		}
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		if strings.HasPrefix(s, `"`) {
+			var value strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, fmt.Errorf("parameter %s has an unterminated quoted value", name)
+			}
+			params[name] = value.String()
+			s = s[i+1:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			params[name] = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+	}
+}
+
+func newDigestChallenge(params map[string]string) (*digestChallenge, error) {
+	c := &digestChallenge{
+		realm:     params["realm"],
+		nonce:     params["nonce"],
+		opaque:    params["opaque"],
+		algorithm: params["algorithm"],
+		userhash:  strings.EqualFold(params["userhash"], "true"),
+	}
+
+	if c.nonce == "" {
+		return nil, fmt.Errorf("challenge has no nonce")
+	}
+
+	if c.algorithm == "" {
+		c.algorithm = "MD5"
+	}
+	if _, ok := digestHashes[strings.TrimSuffix(strings.ToUpper(c.algorithm), "-SESS")]; !ok {
+		return nil, fmt.Errorf("unsupported algorithm %q", c.algorithm)
+	}
+
+	if qop, ok := params["qop"]; ok {
+		offered := map[string]bool{}
+		for _, option := range strings.Split(qop, ",") {
+			offered[strings.ToLower(strings.TrimSpace(option))] = true
+		}
+
+		switch {
+		case offered["auth"]:
+			c.qop = "auth"
+		case offered["auth-int"]:
+			c.qop = "auth-int"
+		default:
+			return nil, fmt.Errorf("unsupported qop %q", qop)
+		}
+	}
+
+	return c, nil
+}
+
+// hash returns the hex encoded digest of s using the challenge's algorithm.
+func (c *digestChallenge) hash(s string) string {
+	h := digestHashes[strings.TrimSuffix(strings.ToUpper(c.algorithm), "-SESS")]()
+	h.Write([]byte(s))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// response returns the request digest proving knowledge of the password.
+func (c *digestChallenge) response(auth *digestAuth, method, uri string, body []byte, cnonce string) string {
+	ha1 := c.hash(auth.username + ":" + c.realm + ":" + auth.password)
+	if strings.HasSuffix(strings.ToUpper(c.algorithm), "-SESS") {
+		ha1 = c.hash(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+
+	a2 := method + ":" + uri
+	if c.qop == "auth-int" {
+		a2 += ":" + c.hash(string(body))
+	}
+	ha2 := c.hash(a2)
+
+	// Without qop the response is computed as in RFC 2069.
+	if c.qop == "" {
+		return c.hash(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	return c.hash(strings.Join([]string{ha1, c.nonce, digestNonceCount, cnonce, c.qop, ha2}, ":"))
+}
+
+// authorization returns the Authorization header value answering the
+// challenge for a request.
+func (c *digestChallenge) authorization(auth *digestAuth, method, uri string, body []byte, cnonce string) string {
+	username := auth.username
+	if c.userhash {
+		username = c.hash(auth.username + ":" + c.realm)
+	}
+
+	params := []string{
+		"username=" + quoteDigestParam(username),
+		"realm=" + quoteDigestParam(c.realm),
+		"nonce=" + quoteDigestParam(c.nonce),
+		"uri=" + quoteDigestParam(uri),
+		"algorithm=" + c.algorithm,
+		"response=" + quoteDigestParam(c.response(auth, method, uri, body, cnonce)),
+	}
+	if c.opaque != "" {
+		params = append(params, "opaque="+quoteDigestParam(c.opaque))
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+digestNonceCount, "cnonce="+quoteDigestParam(cnonce))
+	}
+	if c.userhash {
+		params = append(params, "userhash=true")
+	}
+
+	return "Digest " + strings.Join(params, ", ")
+}
+
+func quoteDigestParam(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDigestChallengeResponse(t *testing.T) {
+	cases := map[string]struct {
+		Challenge *digestChallenge
+		Auth      *digestAuth
+		URI       string
+		CNonce    string
+		Expected  string
+	}{
+		// RFC 2617 section 3.5.
+		"rfc 2617": {
+			Challenge: &digestChallenge{
+				realm:     "testrealm@host.com",
+				nonce:     "dcd98b7102dd2f0e8b11d0f600bfb0c093",
+				algorithm: "MD5",
+				qop:       "auth",
+			},
+			Auth:     &digestAuth{username: "Mufasa", password: "Circle Of Life"},
+			URI:      "/dir/index.html",
+			CNonce:   "0a4f113b",
+			Expected: "6629fae49393a05397450978507c4ef1",
+		},
+		// RFC 7616 section 3.9.1.
+		"rfc 7616 md5": {
+			Challenge: &digestChallenge{
+				realm:     "http-auth@example.org",
+				nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+				algorithm: "MD5",
+				qop:       "auth",
+			},
+			Auth:     &digestAuth{username: "Mufasa", password: "Circle of Life"},
+			URI:      "/dir/index.html",
+			CNonce:   "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+			Expected: "8ca523f5e9506fed4657c9700eebdbec",
+		},
+		"rfc 7616 sha-256": {
+			Challenge: &digestChallenge{
+				realm:     "http-auth@example.org",
+				nonce:     "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+				algorithm: "SHA-256",
+				qop:       "auth",
+			},
+			Auth:     &digestAuth{username: "Mufasa", password: "Circle of Life"},
+			URI:      "/dir/index.html",
+			CNonce:   "f2/wE4q74E6zIJEtWaHKaf5wv/H5QzzpXusqGemxURZJ",
+			Expected: "753927fa0e85d155564e2e272a28d1802ca10daf4496794697cf8db5856cb6c1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := tc.Challenge.response(tc.Auth, http.MethodGet, tc.URI, nil, tc.CNonce); actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+// The example of RFC 7616 section 3.9.2. Its response value does not follow
+// from the inputs given, so the response is not compared with it.
+func TestDigestChallengeAuthorization(t *testing.T) {
+	challenge := &digestChallenge{
+		realm:     "api@example.org",
+		nonce:     "5TsQWLVdgBdmrQ0XsxbDODV+57QdFR34I9HAbC/RVvkK",
+		opaque:    "HRPCssKJSGjCrkzDg8OhwpzCiGPChXYjwrI2QmXDnsOS",
+		algorithm: "SHA-512-256",
+		qop:       "auth",
+		userhash:  true,
+	}
+	auth := &digestAuth{username: "Jäsøn Doe", password: "Secret, or not?"}
+
+	authorization := challenge.authorization(auth, http.MethodGet, "/doc/index.html", nil, "NTg6RKcb9boFIAS3KrFK9BGeh+iDa/sm6jUMp2wds69v")
+
+	params, err := parseDigestParams(authorization[len("Digest "):])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"username":  "793263caabb707a56211940d90411ea4a575adeccb7e360aeb624ed06ece9b0b",
+		"realm":     "api@example.org",
+		"uri":       "/doc/index.html",
+		"algorithm": "SHA-512-256",
+		"nonce":     "5TsQWLVdgBdmrQ0XsxbDODV+57QdFR34I9HAbC/RVvkK",
+		"nc":        "00000001",
+		"cnonce":    "NTg6RKcb9boFIAS3KrFK9BGeh+iDa/sm6jUMp2wds69v",
+		"qop":       "auth",
+		"response":  challenge.response(auth, http.MethodGet, "/doc/index.html", nil, "NTg6RKcb9boFIAS3KrFK9BGeh+iDa/sm6jUMp2wds69v"),
+		"opaque":    "HRPCssKJSGjCrkzDg8OhwpzCiGPChXYjwrI2QmXDnsOS",
+		"userhash":  "true",
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("expected %v, got %v", expected, params)
+	}
+}
+
+func TestDigestChallengeHeader(t *testing.T) {
+	cases := map[string]struct {
+		Headers   []string
+		Expected  *digestChallenge
+		ExpectErr bool
+	}{
+		"none": {
+			Headers: []string{`Basic realm="test"`},
+		},
+		"default algorithm": {
+			Headers: []string{`Digest realm="test", nonce="abc"`},
+			Expected: &digestChallenge{
+				realm:     "test",
+				nonce:     "abc",
+				algorithm: "MD5",
+			},
+		},
+		"quoted": {
+			Headers: []string{`Digest realm="a \"quoted\", realm", qop="auth-int, auth", nonce="abc", opaque=xyz, algorithm=SHA-256-sess`},
+			Expected: &digestChallenge{
+				realm:     `a "quoted", realm`,
+				nonce:     "abc",
+				opaque:    "xyz",
+				algorithm: "SHA-256-sess",
+				qop:       "auth",
+			},
+		},
+		"auth-int": {
+			Headers: []string{`Digest realm="test", qop="auth-int", nonce="abc"`},
+			Expected: &digestChallenge{
+				realm:     "test",
+				nonce:     "abc",
+				algorithm: "MD5",
+				qop:       "auth-int",
+			},
+		},
+		// The first challenge that can be answered is used.
+		"unsupported algorithm first": {
+			Headers: []string{
+				`Digest realm="test", nonce="abc", algorithm=SHA-1`,
+				`Digest realm="test", nonce="def", algorithm=SHA-256, userhash=true`,
+			},
+			Expected: &digestChallenge{
+				realm:     "test",
+				nonce:     "def",
+				algorithm: "SHA-256",
+				userhash:  true,
+			},
+		},
+		"unsupported algorithm": {
+			Headers:   []string{`Digest realm="test", nonce="abc", algorithm=SHA-1`},
+			ExpectErr: true,
+		},
+		"unsupported qop": {
+			Headers:   []string{`Digest realm="test", nonce="abc", qop="other"`},
+			ExpectErr: true,
+		},
+		"missing nonce": {
+			Headers:   []string{`Digest realm="test"`},
+			ExpectErr: true,
+		},
+		"unterminated": {
+			Headers:   []string{`Digest realm="test, nonce="abc`},
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tc.Headers {
+				header.Add("WWW-Authenticate", v)
+			}
+
+			challenge, err := digestChallengeHeader(header)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %#v", challenge)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(challenge, tc.Expected) {
+				t.Fatalf("expected %#v, got %#v", tc.Expected, challenge)
+			}
+		})
+	}
+}
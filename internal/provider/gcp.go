@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/idtoken"
+)
+
+// newGCPIDTokenSource returns a source of Google-signed ID tokens for
+// audience, obtained with the Application Default Credentials: a service
+// account key is exchanged for ID tokens at its token endpoint, and on Google
+// Cloud the metadata server issues them for the attached service account. It
+// is a variable so that tests can replace it.
+var newGCPIDTokenSource = func(ctx context.Context, audience string) (oauth2.TokenSource, error) {
+	return idtoken.NewTokenSource(ctx, audience)
+}
+
+// applyGCPIDToken obtains an ID token for the audience of the gcp_id_token
+// block, if configured, and sets it as the request's bearer token.
+func applyGCPIDToken(ctx context.Context, req *http.Request, d *schema.ResourceData) error {
+	v := d.Get("gcp_id_token").([]interface{})
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("gcp_id_token conflicts with the Authorization request header")
+	}
+
+	m := v[0].(map[string]interface{})
+
+	ts, err := newGCPIDTokenSource(ctx, m["audience"].(string))
+	if err != nil {
+		return fmt.Errorf("Error loading GCP credentials: %s", err)
+	}
+
+	token, err := ts.Token()
+	if err != nil {
+		return fmt.Errorf("Error obtaining GCP ID token: %s", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewGCPIDTokenSource(t *testing.T) {
+	const audience = "https://example.run.app"
+
+	idToken := "eyJhbGciOiJSUzI1NiJ9." +
+		base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"aud":%q,"exp":%d}`, audience, time.Now().Add(time.Hour).Unix()))) +
+		".c2lnbmF0dXJl"
+
+	var assertion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assertion = r.PostForm.Get("assertion")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(fmt.Sprintf(`{"id_token":%q}`, idToken)))
+	}))
+	defer server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "test@example.iam.gserviceaccount.com",
+		"private_key_id": "1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		"token_uri":      server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer setTestGoogleCredentials(t, data)()
+
+	ts, err := newGCPIDTokenSource(context.Background(), audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if token.AccessToken != idToken {
+		t.Fatalf("expected the ID token %s, got %s", idToken, token.AccessToken)
+	}
+
+	// The signed assertion exchanged for the token requests the audience.
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected a JWT assertion, got %q", assertion)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims struct {
+		TargetAudience string `json:"target_audience"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.TargetAudience != audience {
+		t.Fatalf("expected target_audience %s, got %s", audience, claims.TargetAudience)
+	}
+}
+
+func TestNewGCPIDTokenSource_unsupported(t *testing.T) {
+	defer setTestGoogleCredentials(t, []byte(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"token"}`))()
+
+	_, err := newGCPIDTokenSource(context.Background(), "https://example.run.app")
+
+	expected := `idtoken: credential must be service_account, found "authorized_user"`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected error %q, got %v", expected, err)
+	}
+}
+
+// setTestGoogleCredentials makes data the Application Default Credentials
+// until the returned function is called.
+func setTestGoogleCredentials(t *testing.T, data []byte) func() {
+	name := writeTestFile(t, data)
+
+	previous, ok := os.LookupEnv("GOOGLE_APPLICATION_CREDENTIALS")
+	os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", name)
+
+	return func() {
+		if ok {
+			os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", previous)
+		} else {
+			os.Unsetenv("GOOGLE_APPLICATION_CREDENTIALS")
+		}
+		os.Remove(name)
+	}
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// graphQLRequest is the JSON body of a GraphQL request sent over HTTP.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// expandGraphQLBody builds the request body from the graphql block. It
+// returns nil when the block is not set.
+func expandGraphQLBody(v []interface{}) ([]byte, error) {
+	if len(v) == 0 || v[0] == nil {
+		return nil, nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	req := graphQLRequest{
+		Query:         m["query"].(string),
+		OperationName: m["operation_name"].(string),
+	}
+
+	if variables := m["variables"].(string); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return nil, fmt.Errorf("variables must be a JSON object: %s", err)
+		}
+	}
+
+	return json.Marshal(req)
+}
+
+// graphQLErrors returns an error diagnostic for each entry of the errors
+// field of a GraphQL response. Bodies that are not GraphQL responses are
+// ignored.
+func graphQLErrors(body []byte) diag.Diagnostics {
+	var resp struct {
+		Errors []struct {
+			Message string        `json:"message"`
+			Path    []interface{} `json:"path"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil
+	}
+
+	var diags diag.Diagnostics
+	for _, e := range resp.Errors {
+		d := diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("GraphQL error: %s", e.Message),
+		}
+		if len(e.Path) > 0 {
+			d.Detail = fmt.Sprintf("Path: %v", e.Path)
+		}
+		diags = append(diags, d)
+	}
+
+	return diags
+}
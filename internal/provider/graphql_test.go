@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestExpandGraphQLBody(t *testing.T) {
+	cases := map[string]struct {
+		Block     map[string]interface{}
+		Expected  string
+		ExpectErr bool
+	}{
+		"query": {
+			Block:    map[string]interface{}{"query": "{ viewer { login } }", "variables": "", "operation_name": ""},
+			Expected: `{"query":"{ viewer { login } }"}`,
+		},
+		"variables": {
+			Block:    map[string]interface{}{"query": "query Q($id: ID!) { node(id: $id) { id } }", "variables": `{"id": 1}`, "operation_name": "Q"},
+			Expected: `{"query":"query Q($id: ID!) { node(id: $id) { id } }","variables":{"id":1},"operationName":"Q"}`,
+		},
+		"invalid variables": {
+			Block:     map[string]interface{}{"query": "{ a }", "variables": `[1]`, "operation_name": ""},
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := expandGraphQLBody([]interface{}{tc.Block})
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(actual) != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	cases := map[string]struct {
+		Body     string
+		Expected []string
+	}{
+		"data":     {Body: `{"data": {"a": 1}}`},
+		"not json": {Body: `<html></html>`},
+		"errors": {
+			Body:     `{"data": null, "errors": [{"message": "first"}, {"message": "second", "path": ["a", 0]}]}`,
+			Expected: []string{"GraphQL error: first", "GraphQL error: second"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diags := graphQLErrors([]byte(tc.Body))
+			if len(diags) != len(tc.Expected) {
+				t.Fatalf("expected %d diagnostics, got %d", len(tc.Expected), len(diags))
+			}
+			for i, d := range diags {
+				if d.Summary != tc.Expected[i] {
+					t.Fatalf("expected %q, got %q", tc.Expected[i], d.Summary)
+				}
+			}
+		})
+	}
+}
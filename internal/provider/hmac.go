@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// hmacAlgorithms maps the algorithms accepted by the hmac_signature block to
+// their hash constructors.
+var hmacAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// hmacAlgorithmNames are the keys of hmacAlgorithms, for validation.
+var hmacAlgorithmNames = []string{"sha1", "sha256", "sha512"}
+
+// hmacSignature returns the hex encoded HMAC of body keyed with secret.
+func hmacSignature(algorithm, secret string, body []byte) (string, error) {
+	h, ok := hmacAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// applyHMACSignature sets the header named by the hmac_signature block to the
+// HMAC of the request body, if configured.
+func applyHMACSignature(req *http.Request, d *schema.ResourceData, body []byte) error {
+	v := d.Get("hmac_signature").([]interface{})
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+	headerName := m["header_name"].(string)
+
+	if hasRequestHeader(d, headerName) {
+		return fmt.Errorf("hmac_signature conflicts with the %s request header", headerName)
+	}
+
+	signature, err := hmacSignature(m["algorithm"].(string), m["secret"].(string), body)
+	if err != nil {
+		return fmt.Errorf("Error computing hmac_signature: %s", err)
+	}
+
+	req.Header.Set(headerName, m["prefix"].(string)+signature)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestHMACSignature(t *testing.T) {
+	// Expected values from RFC 4231 test case 2 and its SHA-1 equivalent.
+	cases := map[string]struct {
+		Algorithm string
+		Expected  string
+		ExpectErr bool
+	}{
+		"sha1": {
+			Algorithm: "sha1",
+			Expected:  "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79",
+		},
+		"sha256": {
+			Algorithm: "sha256",
+			Expected:  "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		"sha512": {
+			Algorithm: "sha512",
+			Expected:  "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+		},
+		"unsupported": {
+			Algorithm: "md5",
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := hmacSignature(tc.Algorithm, "Jefe", []byte("what do ya want for nothing?"))
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONPath splits a path expression into its object keys and array
+// indexes. Both the dotted form used by GJSON, such as "items.0.name", and
+// the JSONPath form, such as "$.items[0].name", are accepted. Keys that
+// contain dots or brackets can be written as ['key'] or ["key"].
+func parseJSONPath(path string) ([]string, error) {
+	p := path
+	if strings.HasPrefix(p, "$") {
+		p = p[1:]
+	} else if p != "" && p[0] != '[' {
+		// A dotted path without the leading "$.".
+		p = "." + p
+	}
+
+	var segments []string
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			segments = append(segments, p[:end])
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated [ in path %q", path)
+			}
+			segment := p[1:end]
+			if len(segment) >= 2 && (segment[0] == '\'' || segment[0] == '"') && segment[len(segment)-1] == segment[0] {
+				segment = segment[1 : len(segment)-1]
+			} else if _, err := strconv.Atoi(segment); err != nil {
+				return nil, fmt.Errorf("invalid index %q in path %q", segment, path)
+			}
+			segments = append(segments, segment)
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", p[0], path)
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("path %q selects no value", path)
+	}
+
+	return segments, nil
+}
+
+// lookupJSONPath evaluates path against a document decoded with UseNumber.
+// The boolean result is false when the path does not exist.
+func lookupJSONPath(document interface{}, path string) (interface{}, bool, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	v := document
+	for _, segment := range segments {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[segment]
+			if !ok {
+				return nil, false, nil
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false, nil
+			}
+			v = node[i]
+		default:
+			return nil, false, nil
+		}
+	}
+
+	return v, true, nil
+}
+
+// jsonValueString converts a value selected from a JSON document to the
+// string stored in json_values. Strings are returned without quotes, null as
+// an empty string and objects and arrays as compact JSON.
+func jsonValueString(v interface{}) (string, error) {
+	switch value := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// extractJSONValues evaluates every path in paths against the JSON body.
+// Paths that do not match yield an empty string, or an error when strict is
+// set.
+func extractJSONValues(body []byte, paths map[string]interface{}, strict bool) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("response body is not valid JSON: %s", err)
+	}
+
+	values := make(map[string]string, len(paths))
+	for name, path := range paths {
+		v, ok, err := lookupJSONPath(document, path.(string))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %s", name, err)
+		}
+		if !ok {
+			if strict {
+				return nil, fmt.Errorf("key %q: path %q not found in response body", name, path)
+			}
+			values[name] = ""
+			continue
+		}
+
+		if values[name], err = jsonValueString(v); err != nil {
+			return nil, fmt.Errorf("key %q: %s", name, err)
+		}
+	}
+
+	return values, nil
+}
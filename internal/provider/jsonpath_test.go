@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	cases := map[string]struct {
+		Path      string
+		Expected  []string
+		ExpectErr bool
+	}{
+		"dotted":         {Path: "data.items.0.name", Expected: []string{"data", "items", "0", "name"}},
+		"jsonpath":       {Path: "$.data.items[0].name", Expected: []string{"data", "items", "0", "name"}},
+		"root index":     {Path: "$[1]", Expected: []string{"1"}},
+		"quoted key":     {Path: `$['a.b']["c"]`, Expected: []string{"a.b", "c"}},
+		"empty":          {Path: "", ExpectErr: true},
+		"root only":      {Path: "$", ExpectErr: true},
+		"empty key":      {Path: "a..b", ExpectErr: true},
+		"unterminated":   {Path: "$.a[0", ExpectErr: true},
+		"invalid index":  {Path: "$.a[x]", ExpectErr: true},
+		"trailing chars": {Path: "$.a[0]b", ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := parseJSONPath(tc.Path)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(actual, tc.Expected) {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestExtractJSONValues(t *testing.T) {
+	body := []byte(`{"data": {"id": 12345678901234567890, "enabled": true, "owner": null, "items": [{"name": "first"}, {"name": "<second>"}]}}`)
+
+	paths := map[string]interface{}{
+		"id":      "data.id",
+		"enabled": "$.data.enabled",
+		"owner":   "$.data.owner",
+		"second":  "$.data.items[1].name",
+		"first":   "data.items.0",
+		"missing": "$.data.items[2].name",
+	}
+
+	actual, err := extractJSONValues(body, paths, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]string{
+		"id":      "12345678901234567890",
+		"enabled": "true",
+		"owner":   "",
+		"second":  "<second>",
+		"first":   `{"name":"first"}`,
+		"missing": "",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	if _, err := extractJSONValues(body, paths, true); err == nil {
+		t.Fatal("expected error for missing path in strict mode, got none")
+	}
+
+	if _, err := extractJSONValues([]byte("not json"), paths, false); err == nil {
+		t.Fatal("expected error for invalid JSON, got none")
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// jsonSchemaURL is the name the inline json_schema is compiled under. Relative
+// references in it resolve against the working directory.
+const jsonSchemaURL = "json_schema.json"
+
+// compileJSONSchema compiles the schema given by json_schema or
+// json_schema_file, returning nil if neither is set.
+func compileJSONSchema(d *schema.ResourceData) (*jsonschema.Schema, error) {
+	if path := d.Get("json_schema_file").(string); path != "" {
+		s, err := jsonschema.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error compiling json_schema_file: %s", err)
+		}
+		return s, nil
+	}
+
+	v := d.Get("json_schema").(string)
+	if v == "" {
+		return nil, nil
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(jsonSchemaURL, strings.NewReader(v)); err != nil {
+		return nil, fmt.Errorf("Error compiling json_schema: %s", err)
+	}
+
+	s, err := compiler.Compile(jsonSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("Error compiling json_schema: %s", err)
+	}
+
+	return s, nil
+}
+
+// jsonSchemaViolations validates the JSON document body against s, returning
+// an error diagnostic for each violation.
+func jsonSchemaViolations(s *jsonschema.Schema, body []byte) diag.Diagnostics {
+	// Numbers are kept as written so that large integers are compared exactly.
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return diag.Errorf("Error parsing response body for json_schema: %s", err)
+	}
+
+	err := s.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return diag.Errorf("Error validating response body against json_schema: %s", err)
+	}
+
+	// Properties are validated in no particular order, so the violations are
+	// sorted by where they occur.
+	leaves := leafValidationErrors(validationErr)
+	sort.SliceStable(leaves, func(i, j int) bool {
+		if leaves[i].InstanceLocation != leaves[j].InstanceLocation {
+			return leaves[i].InstanceLocation < leaves[j].InstanceLocation
+		}
+		return leaves[i].KeywordLocation < leaves[j].KeywordLocation
+	})
+
+	var diags diag.Diagnostics
+	for _, e := range leaves {
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Response body does not match json_schema at %s: %s", location, e.Message),
+			Detail:   fmt.Sprintf("Violated schema keyword: %s", e.KeywordLocation),
+		})
+	}
+
+	return diags
+}
+
+// leafValidationErrors returns the innermost errors of e, which describe the
+// violations. The others name the schemas that failed because of them.
+func leafValidationErrors(e *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(e.Causes) == 0 {
+		return []*jsonschema.ValidationError{e}
+	}
+
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range e.Causes {
+		leaves = append(leaves, leafValidationErrors(cause)...)
+	}
+
+	return leaves
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const testJSONSchema = `{
+  "type": "object",
+  "required": ["version"],
+  "properties": {
+    "version": {"type": "string"},
+    "count": {"type": "integer", "maximum": 10},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func TestCompileJSONSchema(t *testing.T) {
+	schemaFile, err := ioutil.TempFile("", "json_schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(schemaFile.Name())
+
+	if _, err := schemaFile.WriteString(testJSONSchema); err != nil {
+		t.Fatal(err)
+	}
+	schemaFile.Close()
+
+	cases := map[string]struct {
+		Raw       map[string]interface{}
+		ExpectNil bool
+		ExpectErr bool
+	}{
+		"unset":          {Raw: map[string]interface{}{}, ExpectNil: true},
+		"inline":         {Raw: map[string]interface{}{"json_schema": testJSONSchema}},
+		"file":           {Raw: map[string]interface{}{"json_schema_file": schemaFile.Name()}},
+		"invalid schema": {Raw: map[string]interface{}{"json_schema": `{"type": "objects"}`}, ExpectErr: true},
+		"missing file":   {Raw: map[string]interface{}{"json_schema_file": schemaFile.Name() + ".missing"}, ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSource().Schema, tc.Raw)
+
+			s, err := compileJSONSchema(d)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if (s == nil) != tc.ExpectNil {
+				t.Fatalf("expected nil schema %t, got %v", tc.ExpectNil, s)
+			}
+		})
+	}
+}
+
+func TestJSONSchemaViolations(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{"json_schema": testJSONSchema})
+
+	s, err := compileJSONSchema(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		Body     string
+		Expected []string
+	}{
+		"valid": {
+			Body: `{"version": "1.0.0", "count": 3, "tags": ["a"]}`,
+		},
+		"large integer": {
+			Body:     `{"version": "1.0.0", "count": 10000000000000000001}`,
+			Expected: []string{"Response body does not match json_schema at /count: must be <= 10 but found 10000000000000000001"},
+		},
+		"violations": {
+			Body: `{"count": 1.5, "tags": ["a", 2]}`,
+			Expected: []string{
+				"Response body does not match json_schema at /: missing properties: 'version'",
+				"Response body does not match json_schema at /count: expected integer, but got number",
+				"Response body does not match json_schema at /tags/1: expected string, but got number",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var actual []string
+			for _, d := range jsonSchemaViolations(s, []byte(tc.Body)) {
+				actual = append(actual, d.Summary)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Expected) {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
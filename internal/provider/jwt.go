@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// jwtAlgorithmNames are the signing algorithms accepted by the jwt block.
+var jwtAlgorithmNames = []string{"RS256", "HS256", "ES256"}
+
+// jwtTimeClaims are the registered claims holding a time as seconds since the
+// epoch, which are encoded as numbers rather than strings.
+var jwtTimeClaims = map[string]bool{
+	"exp": true,
+	"iat": true,
+	"nbf": true,
+}
+
+// jwtConfig holds the settings of the jwt block.
+type jwtConfig struct {
+	signingKey string
+	algorithm  string
+	keyID      string
+	claims     map[string]interface{}
+	ttl        time.Duration
+}
+
+func expandJWTConfig(v []interface{}) *jwtConfig {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return &jwtConfig{
+		signingKey: m["signing_key"].(string),
+		algorithm:  m["algorithm"].(string),
+		keyID:      m["key_id"].(string),
+		claims:     m["claims"].(map[string]interface{}),
+		ttl:        time.Duration(m["ttl_ms"].(int)) * time.Millisecond,
+	}
+}
+
+// signJWT returns a JWT with the configured claims, signed with the signing
+// key. iat is set to now and exp to now plus the ttl, unless they are given as
+// claims.
+func signJWT(c *jwtConfig, now time.Time) (string, error) {
+	claims := map[string]interface{}{
+		"iat": now.Unix(),
+		"exp": now.Add(c.ttl).Unix(),
+	}
+	for name, value := range c.claims {
+		if !jwtTimeClaims[name] {
+			claims[name] = value
+			continue
+		}
+
+		seconds, err := strconv.ParseInt(value.(string), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("claim %q must be a number of seconds since the epoch, got %q", name, value)
+		}
+		claims[name] = seconds
+	}
+
+	header, err := json.Marshal(struct {
+		Algorithm string `json:"alg"`
+		Type      string `json:"typ"`
+		KeyID     string `json:"kid,omitempty"`
+	}{c.algorithm, "JWT", c.keyID})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	signature, err := jwtSignature(c.algorithm, c.signingKey, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// jwtSignature signs input with key, a shared secret for HS256 and a PEM
+// encoded private key otherwise.
+func jwtSignature(algorithm, key string, input []byte) ([]byte, error) {
+	if algorithm == "HS256" {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	}
+
+	privateKey, err := parsePrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256(input)
+
+	switch algorithm {
+	case "RS256":
+		rsaKey, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("RS256 requires an RSA private key")
+		}
+		return rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		ecKey, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 requires an ECDSA private key on the P-256 curve")
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// The signature is r and s as 32 byte big-endian integers, rather
+		// than the ASN.1 encoding used elsewhere.
+		signature := make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(signature[32-len(rBytes):32], rBytes)
+		copy(signature[64-len(sBytes):], sBytes)
+		return signature, nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+}
+
+// parsePrivateKeyPEM parses the first PEM block of data as a PKCS #1 RSA,
+// SEC 1 EC or PKCS #8 private key.
+func parsePrivateKeyPEM(data string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded private key found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// applyJWT mints a JWT from the jwt block, if configured, and sets it as the
+// request's bearer token.
+func applyJWT(req *http.Request, d *schema.ResourceData) error {
+	c := expandJWTConfig(d.Get("jwt").([]interface{}))
+	if c == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("jwt conflicts with the Authorization request header")
+	}
+
+	token, err := signJWT(c, time.Now())
+	if err != nil {
+		return fmt.Errorf("Error signing jwt: %s", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifyJWT checks the signature of token with the public key, or the shared
+// secret for HS256, returning its decoded header and claims.
+func verifyJWT(token string, key interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("expected a JWT, got %q", token)
+	}
+
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, nil, err
+		}
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return nil, nil, fmt.Errorf("expected a 64 byte signature, got %d bytes", len(signature))
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(k, digest[:], r, s) {
+			return nil, nil, fmt.Errorf("invalid ECDSA signature")
+		}
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return nil, nil, fmt.Errorf("invalid HMAC signature")
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	return header, claims, nil
+}
+
+func TestSignJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384DER, err := x509.MarshalECPrivateKey(p384Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	pkcs8PEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	ecPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}))
+	p384PEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: p384DER}))
+
+	now := time.Unix(1600000000, 0)
+
+	cases := map[string]struct {
+		Config       jwtConfig
+		VerifyKey    interface{}
+		ExpectHeader map[string]interface{}
+		ExpectClaims map[string]interface{}
+		ExpectErr    string
+	}{
+		"RS256": {
+			Config:       jwtConfig{signingKey: rsaPEM, algorithm: "RS256", claims: map[string]interface{}{"sub": "test"}, ttl: 5 * time.Minute},
+			VerifyKey:    &rsaKey.PublicKey,
+			ExpectHeader: map[string]interface{}{"alg": "RS256", "typ": "JWT"},
+			ExpectClaims: map[string]interface{}{"sub": "test", "iat": float64(1600000000), "exp": float64(1600000300)},
+		},
+		"RS256 PKCS #8 key": {
+			Config:       jwtConfig{signingKey: pkcs8PEM, algorithm: "RS256", claims: map[string]interface{}{}, ttl: time.Minute},
+			VerifyKey:    &rsaKey.PublicKey,
+			ExpectHeader: map[string]interface{}{"alg": "RS256", "typ": "JWT"},
+			ExpectClaims: map[string]interface{}{"iat": float64(1600000000), "exp": float64(1600000060)},
+		},
+		"ES256": {
+			Config:       jwtConfig{signingKey: ecPEM, algorithm: "ES256", keyID: "key-1", claims: map[string]interface{}{"aud": "api"}, ttl: time.Minute},
+			VerifyKey:    &ecKey.PublicKey,
+			ExpectHeader: map[string]interface{}{"alg": "ES256", "typ": "JWT", "kid": "key-1"},
+			ExpectClaims: map[string]interface{}{"aud": "api", "iat": float64(1600000000), "exp": float64(1600000060)},
+		},
+		"HS256": {
+			Config:       jwtConfig{signingKey: "secret", algorithm: "HS256", claims: map[string]interface{}{"sub": "test"}, ttl: time.Minute},
+			VerifyKey:    []byte("secret"),
+			ExpectHeader: map[string]interface{}{"alg": "HS256", "typ": "JWT"},
+			ExpectClaims: map[string]interface{}{"sub": "test", "iat": float64(1600000000), "exp": float64(1600000060)},
+		},
+		"time claims given": {
+			Config:       jwtConfig{signingKey: "secret", algorithm: "HS256", claims: map[string]interface{}{"iat": "1500000000", "exp": "1500000100", "nbf": "1500000000"}, ttl: time.Minute},
+			VerifyKey:    []byte("secret"),
+			ExpectHeader: map[string]interface{}{"alg": "HS256", "typ": "JWT"},
+			ExpectClaims: map[string]interface{}{"iat": float64(1500000000), "exp": float64(1500000100), "nbf": float64(1500000000)},
+		},
+		"time claim not a number": {
+			Config:    jwtConfig{signingKey: "secret", algorithm: "HS256", claims: map[string]interface{}{"exp": "tomorrow"}, ttl: time.Minute},
+			ExpectErr: `claim "exp" must be a number of seconds since the epoch, got "tomorrow"`,
+		},
+		"not PEM": {
+			Config:    jwtConfig{signingKey: "secret", algorithm: "RS256", claims: map[string]interface{}{}, ttl: time.Minute},
+			ExpectErr: "no PEM encoded private key found",
+		},
+		"RS256 with EC key": {
+			Config:    jwtConfig{signingKey: ecPEM, algorithm: "RS256", claims: map[string]interface{}{}, ttl: time.Minute},
+			ExpectErr: "RS256 requires an RSA private key",
+		},
+		"ES256 with P-384 key": {
+			Config:    jwtConfig{signingKey: p384PEM, algorithm: "ES256", claims: map[string]interface{}{}, ttl: time.Minute},
+			ExpectErr: "ES256 requires an ECDSA private key on the P-256 curve",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			token, err := signJWT(&tc.Config, now)
+			if tc.ExpectErr != "" {
+				if err == nil || err.Error() != tc.ExpectErr {
+					t.Fatalf("expected error %q, got %v", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			header, claims, err := verifyJWT(token, tc.VerifyKey)
+			if err != nil {
+				t.Fatalf("error verifying %s: %s", token, err)
+			}
+			if !reflect.DeepEqual(header, tc.ExpectHeader) {
+				t.Fatalf("expected header %v, got %v", tc.ExpectHeader, header)
+			}
+			if !reflect.DeepEqual(claims, tc.ExpectClaims) {
+				t.Fatalf("expected claims %v, got %v", tc.ExpectClaims, claims)
+			}
+		})
+	}
+}
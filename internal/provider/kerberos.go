@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// defaultKrb5ConfPath is the krb5.conf read when neither krb5_conf nor kdcs
+// is set and KRB5_CONFIG does not name another file.
+const defaultKrb5ConfPath = "/etc/krb5.conf"
+
+// kerberosAuth holds the settings of the kerberos_auth block.
+type kerberosAuth struct {
+	username   string
+	realm      string
+	password   string
+	keytabFile string
+	ccacheFile string
+	krb5Conf   string
+	kdcs       []string
+	spn        string
+}
+
+func expandKerberosAuth(v []interface{}) *kerberosAuth {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	var kdcs []string
+	for _, kdc := range m["kdcs"].([]interface{}) {
+		kdcs = append(kdcs, kdc.(string))
+	}
+
+	return &kerberosAuth{
+		username:   m["username"].(string),
+		realm:      m["realm"].(string),
+		password:   m["password"].(string),
+		keytabFile: m["keytab_file"].(string),
+		ccacheFile: m["ccache_file"].(string),
+		krb5Conf:   m["krb5_conf"].(string),
+		kdcs:       kdcs,
+		spn:        m["spn"].(string),
+	}
+}
+
+// applyKerberosAuth wraps the client's transport to perform SPNEGO
+// authentication from the kerberos_auth block, if configured. A client that
+// logged in keeps renewing its ticket in the background, so the returned
+// function, which destroys it, must be called once the request is done.
+func applyKerberosAuth(httpClient *http.Client, d *schema.ResourceData) (func(), error) {
+	auth := expandKerberosAuth(d.Get("kerberos_auth").([]interface{}))
+	if auth == nil {
+		return func() {}, nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return nil, fmt.Errorf("kerberos_auth conflicts with the Authorization request header")
+	}
+
+	cl, err := auth.newClient()
+	if err != nil {
+		return nil, fmt.Errorf("Error configuring kerberos_auth: %s", err)
+	}
+
+	httpClient.Transport = &kerberosTransport{base: httpClient.Transport, client: cl, spn: auth.spn}
+
+	return cl.Destroy, nil
+}
+
+// newClient returns a Kerberos client for the credentials of the block. It
+// does not contact the KDC, which only happens once a server asks for
+// authentication.
+func (a *kerberosAuth) newClient() (*client.Client, error) {
+	// Active Directory does not support the PA-FX-FAST pre-authentication
+	// data, and other KDCs do not require it.
+	settings := client.DisablePAFXFAST(true)
+
+	if a.ccacheFile != "" {
+		ccache, err := loadCCache(a.ccacheFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ccache_file: %s", err)
+		}
+
+		realm := a.realm
+		if realm == "" {
+			realm = ccache.GetClientRealm()
+		}
+		krb5conf, err := a.config(realm)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.NewFromCCache(ccache, krb5conf, settings)
+	}
+
+	krb5conf, err := a.config(a.realm)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.keytabFile != "" {
+		kt, err := keytab.Load(a.keytabFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading keytab_file: %s", err)
+		}
+		return client.NewWithKeytab(a.username, a.realm, kt, krb5conf, settings), nil
+	}
+
+	return client.NewWithPassword(a.username, a.realm, a.password, krb5conf, settings), nil
+}
+
+// config returns the Kerberos configuration given by krb5_conf or kdcs, or
+// otherwise read from the system's krb5.conf, if there is one.
+func (a *kerberosAuth) config(realm string) (*config.Config, error) {
+	if a.krb5Conf != "" {
+		c, err := config.NewFromString(a.krb5Conf)
+		if err != nil {
+			return nil, fmt.Errorf("parsing krb5_conf: %s", err)
+		}
+		return c, nil
+	}
+
+	if len(a.kdcs) > 0 {
+		c, err := config.NewFromString(krb5ConfForKDCs(realm, a.kdcs))
+		if err != nil {
+			return nil, fmt.Errorf("configuring kdcs: %s", err)
+		}
+		return c, nil
+	}
+
+	path := os.Getenv("KRB5_CONFIG")
+	if path == "" {
+		path = defaultKrb5ConfPath
+		// Without a krb5.conf the KDCs of the realm are found through DNS.
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			c := config.New()
+			c.LibDefaults.DefaultRealm = realm
+			c.LibDefaults.DNSLookupKDC = true
+			return c, nil
+		}
+	}
+
+	c, err := config.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", path, err)
+	}
+
+	return c, nil
+}
+
+// krb5ConfForKDCs returns a krb5.conf that sends requests for realm to kdcs.
+func krb5ConfForKDCs(realm string, kdcs []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[libdefaults]\n default_realm = %s\n dns_lookup_kdc = false\n", realm)
+	fmt.Fprintf(&b, "[realms]\n %s = {\n", realm)
+	for _, kdc := range kdcs {
+		fmt.Fprintf(&b, "  kdc = %s\n", kdc)
+	}
+	b.WriteString(" }\n")
+
+	return b.String()
+}
+
+// loadCCache reads a credential cache file. The parser panics on truncated
+// files, which is returned as an error instead.
+func loadCCache(path string) (ccache *credentials.CCache, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ccache, err = nil, fmt.Errorf("invalid credential cache")
+		}
+	}()
+
+	return credentials.LoadCCache(path)
+}
+
+// kerberosTransport answers a 401 response offering Negotiate by resending the
+// request with a SPNEGO token carrying a Kerberos service ticket, obtained
+// from the KDC unless the credential cache holds one.
+type kerberosTransport struct {
+	base   http.RoundTripper
+	client *client.Client
+	// spn is the service principal name, by default HTTP/<host>.
+	spn string
+}
+
+func (t *kerberosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !offersNegotiate(resp.Header) {
+		return resp, err
+	}
+	discardBody(resp)
+
+	spn := t.spn
+	if spn == "" {
+		spn = "HTTP/" + req.URL.Hostname()
+	}
+
+	authorization, err := negotiateAuthorization(t.client, spn)
+	if err != nil {
+		return nil, fmt.Errorf("Error obtaining Kerberos service ticket for %s: %s", spn, err)
+	}
+
+	authReq, err := withAuthorization(req, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(authReq)
+}
+
+// negotiateAuthorization returns the Authorization header value carrying a
+// SPNEGO token for spn, logging in to the KDC first if the client has no
+// ticket-granting ticket.
+func negotiateAuthorization(cl *client.Client, spn string) (string, error) {
+	s := spnego.SPNEGOClient(cl, spn)
+	if err := s.AcquireCred(); err != nil {
+		return "", err
+	}
+
+	token, err := s.InitSecContext()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := token.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return "Negotiate " + base64.StdEncoding.EncodeToString(b), nil
+}
+
+// offersNegotiate reports whether a WWW-Authenticate header offers Negotiate.
+func offersNegotiate(header http.Header) bool {
+	for _, v := range header.Values("WWW-Authenticate") {
+		if fields := strings.Fields(v); len(fields) > 0 && strings.EqualFold(fields[0], "Negotiate") {
+			return true
+		}
+	}
+
+	return false
+}
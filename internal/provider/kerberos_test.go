@@ -0,0 +1,322 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/goidentity/v6"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	testKerberosRealm = "EXAMPLE.COM"
+	testKerberosUser  = "alice"
+	testKrb5Conf      = "[libdefaults]\n default_realm = EXAMPLE.COM\n dns_lookup_kdc = false\n"
+)
+
+// testKerberosTicket is a ticket for a credential cache, issued without a KDC
+// by encrypting it with the service key in a keytab.
+type testKerberosTicket struct {
+	sname  types.PrincipalName
+	ticket []byte
+	key    types.EncryptionKey
+}
+
+func newTestKerberosTicket(t *testing.T, spn string) (testKerberosTicket, *keytab.Keytab) {
+	kt := keytab.New()
+	if err := kt.AddEntry(spn, testKerberosRealm, "service-password", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96); err != nil {
+		t.Fatal(err)
+	}
+
+	sname, _ := types.ParseSPNString(spn)
+	if len(sname.NameString) > 1 {
+		sname.NameType = nametype.KRB_NT_SRV_INST
+	}
+	now := time.Now().UTC()
+	tkt, key, err := messages.NewTicket(
+		types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, testKerberosUser), testKerberosRealm,
+		sname, testKerberosRealm, types.NewKrbFlags(), kt, etypeID.AES256_CTS_HMAC_SHA1_96, 1,
+		now, now, now.Add(time.Hour), now.Add(time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := tkt.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testKerberosTicket{sname: sname, ticket: b, key: key}, kt
+}
+
+// writeTestCCache writes the tickets of alice@EXAMPLE.COM to a version 4
+// credential cache file, returning its path.
+func writeTestCCache(t *testing.T, tickets ...testKerberosTicket) string {
+	var b bytes.Buffer
+	write := func(v interface{}) { binary.Write(&b, binary.BigEndian, v) }
+	writeData := func(data []byte) {
+		write(uint32(len(data)))
+		b.Write(data)
+	}
+	writePrincipal := func(name types.PrincipalName) {
+		write(name.NameType)
+		write(uint32(len(name.NameString)))
+		writeData([]byte(testKerberosRealm))
+		for _, s := range name.NameString {
+			writeData([]byte(s))
+		}
+	}
+
+	cname := types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, testKerberosUser)
+	now := uint32(time.Now().Unix())
+
+	b.Write([]byte{5, 4})
+	write(uint16(0))
+	writePrincipal(cname)
+	for _, tkt := range tickets {
+		writePrincipal(cname)
+		writePrincipal(tkt.sname)
+		write(uint16(tkt.key.KeyType))
+		writeData(tkt.key.KeyValue)
+		write([]uint32{now, now, now + 3600, now + 3600})
+		write(uint8(0))
+		write([]byte{0, 0, 0, 0})
+		write(uint32(0))
+		write(uint32(0))
+		writeData(tkt.ticket)
+		writeData(nil)
+	}
+
+	return writeTestFile(t, b.Bytes())
+}
+
+func writeTestFile(t *testing.T, data []byte) string {
+	f, err := ioutil.TempFile("", "kerberos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+// newKerberosTestServer returns a server that requires SPNEGO authentication
+// for HTTP/127.0.0.1 and responds with the name of the authenticated user,
+// together with a credential cache holding a ticket for it.
+func newKerberosTestServer(t *testing.T) (*httptest.Server, string) {
+	tgt, _ := newTestKerberosTicket(t, "krbtgt/"+testKerberosRealm)
+	service, kt := newTestKerberosTicket(t, "HTTP/127.0.0.1")
+
+	server := httptest.NewServer(spnego.SPNEGOKRB5Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(goidentity.FromHTTPRequestContext(r).UserName()))
+	}), kt))
+
+	return server, writeTestCCache(t, tgt, service)
+}
+
+func TestKerberosTransport(t *testing.T) {
+	server, ccacheFile := newKerberosTestServer(t)
+	defer server.Close()
+	defer os.Remove(ccacheFile)
+
+	cases := map[string]struct {
+		SPN          string
+		ExpectBody   string
+		ExpectStatus int
+		ExpectErr    string
+	}{
+		"default spn": {
+			ExpectBody:   testKerberosUser,
+			ExpectStatus: http.StatusOK,
+		},
+		"spn": {
+			SPN:          "HTTP/127.0.0.1",
+			ExpectBody:   testKerberosUser,
+			ExpectStatus: http.StatusOK,
+		},
+		// Without a KDC only the tickets in the credential cache are known.
+		"spn without ticket": {
+			SPN:       "HTTP/www.example.com",
+			ExpectErr: "Error obtaining Kerberos service ticket for HTTP/www.example.com",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			auth := &kerberosAuth{ccacheFile: ccacheFile, krb5Conf: testKrb5Conf, spn: tc.SPN}
+			cl, err := auth.newClient()
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer cl.Destroy()
+
+			client := &http.Client{Transport: &kerberosTransport{base: http.DefaultTransport, client: cl, spn: auth.spn}}
+			resp, err := client.Get(server.URL)
+			if tc.ExpectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.ExpectErr) {
+					t.Fatalf("expected error containing %q, got %v", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tc.ExpectStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.ExpectStatus, resp.StatusCode, body)
+			}
+			if string(body) != tc.ExpectBody {
+				t.Fatalf("expected body %q, got %q", tc.ExpectBody, body)
+			}
+		})
+	}
+}
+
+func TestKerberosTransport_notNegotiate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// The client is never used, as the server does not offer Negotiate.
+	client := &http.Client{Transport: &kerberosTransport{base: http.DefaultTransport}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestKerberosAuthNewClient(t *testing.T) {
+	service, _ := newTestKerberosTicket(t, "HTTP/127.0.0.1")
+	withoutTGT := writeTestCCache(t, service)
+	defer os.Remove(withoutTGT)
+	// The default principal's realm is longer than the file.
+	truncated := writeTestFile(t, []byte{5, 4, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0x7f, 0xff, 0xff, 0xff})
+	defer os.Remove(truncated)
+	notCCache := writeTestFile(t, []byte("not a credential cache"))
+	defer os.Remove(notCCache)
+
+	cases := map[string]struct {
+		Auth      kerberosAuth
+		ExpectErr string
+	}{
+		"password": {
+			Auth: kerberosAuth{username: testKerberosUser, realm: testKerberosRealm, password: "secret", kdcs: []string{"127.0.0.1:88"}},
+		},
+		"missing keytab_file": {
+			Auth:      kerberosAuth{username: testKerberosUser, realm: testKerberosRealm, keytabFile: "/nonexistent/krb5.keytab", krb5Conf: testKrb5Conf},
+			ExpectErr: "reading keytab_file: open /nonexistent/krb5.keytab: no such file or directory",
+		},
+		"missing ccache_file": {
+			Auth:      kerberosAuth{ccacheFile: "/nonexistent/krb5cc", krb5Conf: testKrb5Conf},
+			ExpectErr: "reading ccache_file: open /nonexistent/krb5cc: no such file or directory",
+		},
+		"truncated ccache_file": {
+			Auth:      kerberosAuth{ccacheFile: truncated, krb5Conf: testKrb5Conf},
+			ExpectErr: "reading ccache_file: invalid credential cache",
+		},
+		"ccache_file not a credential cache": {
+			Auth:      kerberosAuth{ccacheFile: notCCache, krb5Conf: testKrb5Conf},
+			ExpectErr: "reading ccache_file: Invalid credential cache data. First byte does not equal 5",
+		},
+		"ccache_file without ticket-granting ticket": {
+			Auth:      kerberosAuth{ccacheFile: withoutTGT, krb5Conf: testKrb5Conf},
+			ExpectErr: "TGT not found in CCache",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cl, err := tc.Auth.newClient()
+			if tc.ExpectErr != "" {
+				if err == nil || err.Error() != tc.ExpectErr {
+					t.Fatalf("expected error %q, got %v", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			cl.Destroy()
+		})
+	}
+}
+
+func TestKrb5ConfForKDCs(t *testing.T) {
+	c, err := config.NewFromString(krb5ConfForKDCs("EXAMPLE.COM", []string{"kdc1.example.com:88", "kdc2.example.com"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if c.LibDefaults.DefaultRealm != "EXAMPLE.COM" {
+		t.Fatalf("expected default realm EXAMPLE.COM, got %s", c.LibDefaults.DefaultRealm)
+	}
+	if c.LibDefaults.DNSLookupKDC {
+		t.Fatalf("expected dns_lookup_kdc to be false")
+	}
+
+	if len(c.Realms) != 1 || c.Realms[0].Realm != "EXAMPLE.COM" {
+		t.Fatalf("expected the realm EXAMPLE.COM, got %v", c.Realms)
+	}
+	if expected := []string{"kdc1.example.com:88", "kdc2.example.com:88"}; !reflect.DeepEqual(c.Realms[0].KDC, expected) {
+		t.Fatalf("expected KDCs %v, got %v", expected, c.Realms[0].KDC)
+	}
+}
+
+func TestOffersNegotiate(t *testing.T) {
+	cases := map[string]struct {
+		Values   []string
+		Expected bool
+	}{
+		"negotiate":           {Values: []string{"Negotiate"}, Expected: true},
+		"negotiate with data": {Values: []string{"Negotiate YIIC"}, Expected: true},
+		"case insensitive":    {Values: []string{"negotiate"}, Expected: true},
+		"among others":        {Values: []string{`Basic realm="test"`, "Negotiate"}, Expected: true},
+		"other scheme":        {Values: []string{"NTLM"}},
+		"none":                {},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tc.Values {
+				header.Add("WWW-Authenticate", v)
+			}
+			if actual := offersNegotiate(header); actual != tc.Expected {
+				t.Fatalf("expected %t, got %t", tc.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+)
+
+// multipartField is a single field of the multipart block. Fields with a
+// filePath are sent as file parts, the others as plain values.
+type multipartField struct {
+	name     string
+	value    string
+	filePath string
+}
+
+func expandMultipartFields(v []interface{}) []multipartField {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	var fields []multipartField
+	for _, f := range m["field"].([]interface{}) {
+		field := f.(map[string]interface{})
+		fields = append(fields, multipartField{
+			name:     field["name"].(string),
+			value:    field["value"].(string),
+			filePath: field["file_path"].(string),
+		})
+	}
+
+	return fields
+}
+
+// multipartBody streams a multipart/form-data request body. Files are read
+// while the body is being sent rather than loaded up front, and every call to
+// open starts a new stream so that the body can be resent on retries.
+type multipartBody struct {
+	fields   []multipartField
+	boundary string
+}
+
+// newMultipartBody checks that the fields are valid and that every file can
+// be read, so that problems are reported before the request is sent.
+func newMultipartBody(fields []multipartField) (*multipartBody, error) {
+	for _, field := range fields {
+		if field.filePath == "" {
+			continue
+		}
+
+		if field.value != "" {
+			return nil, fmt.Errorf("multipart field %q: value conflicts with file_path", field.name)
+		}
+
+		f, err := os.Open(field.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("multipart field %q: %s", field.name, err)
+		}
+		f.Close()
+	}
+
+	return &multipartBody{
+		fields:   fields,
+		boundary: multipart.NewWriter(nil).Boundary(),
+	}, nil
+}
+
+// contentType returns the Content-Type header value, including the boundary.
+func (b *multipartBody) contentType() string {
+	return "multipart/form-data; boundary=" + b.boundary
+}
+
+// open returns a reader that produces the encoded body.
+func (b *multipartBody) open() (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(b.write(pw))
+	}()
+
+	return pr, nil
+}
+
+func (b *multipartBody) write(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	if err := mw.SetBoundary(b.boundary); err != nil {
+		return err
+	}
+
+	for _, field := range b.fields {
+		if field.filePath == "" {
+			if err := mw.WriteField(field.name, field.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		part, err := mw.CreateFormFile(field.name, filepath.Base(field.filePath))
+		if err != nil {
+			return err
+		}
+
+		if err := copyFile(part, field.filePath); err != nil {
+			return fmt.Errorf("multipart field %q: %s", field.name, err)
+		}
+	}
+
+	return mw.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"os"
+	"testing"
+)
+
+func TestMultipartBody(t *testing.T) {
+	file, err := ioutil.TempFile("", "multipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString("file contents"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	body, err := newMultipartBody([]multipartField{
+		{name: "description", value: "a test upload"},
+		{name: "upload", filePath: file.Name()},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, params, err := mime.ParseMediaType(body.contentType())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The body is opened again for every retry and must be identical.
+	for i := 0; i < 2; i++ {
+		r, err := body.open()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		form, err := multipart.NewReader(r, params["boundary"]).ReadForm(1 << 20)
+		r.Close()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if v := form.Value["description"]; len(v) != 1 || v[0] != "a test upload" {
+			t.Fatalf("unexpected description field %q", v)
+		}
+
+		files := form.File["upload"]
+		if len(files) != 1 || files[0].Size != int64(len("file contents")) {
+			t.Fatalf("unexpected upload field %v", files)
+		}
+	}
+}
+
+func TestNewMultipartBody_errors(t *testing.T) {
+	cases := map[string][]multipartField{
+		"missing file":            {{name: "upload", filePath: "does-not-exist"}},
+		"value and file conflict": {{name: "upload", value: "x", filePath: os.Args[0]}},
+	}
+
+	for name, fields := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newMultipartBody(fields); err == nil {
+				t.Fatal("expected error, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/crypto/md4"
+)
+
+// The NTLM message formats and the NTLMv2 response are described in
+// [MS-NLMP]: NT LAN Manager (NTLM) Authentication Protocol.
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+const (
+	ntlmNegotiateUnicode                 = 0x00000001
+	ntlmNegotiateOEM                     = 0x00000002
+	ntlmRequestTarget                    = 0x00000004
+	ntlmNegotiateNTLM                    = 0x00000200
+	ntlmNegotiateAlwaysSign              = 0x00008000
+	ntlmNegotiateExtendedSessionSecurity = 0x00080000
+	ntlmNegotiateTargetInfo              = 0x00800000
+	ntlmNegotiate128                     = 0x20000000
+	ntlmNegotiate56                      = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmNegotiateOEM | ntlmRequestTarget |
+		ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSessionSecurity |
+		ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+
+	// ntlmAvTimestamp is the AV_PAIR ID of the server's FILETIME in the
+	// challenge's target information.
+	ntlmAvTimestamp = 7
+)
+
+// ntlmAuth holds the credentials of the ntlm_auth block.
+type ntlmAuth struct {
+	username string
+	password string
+	domain   string
+}
+
+func expandNTLMAuth(v []interface{}) *ntlmAuth {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return &ntlmAuth{
+		username: m["username"].(string),
+		password: m["password"].(string),
+		domain:   m["domain"].(string),
+	}
+}
+
+// applyNTLMAuth wraps the client's transport to perform NTLM authentication
+// from the ntlm_auth block, if configured.
+func applyNTLMAuth(client *http.Client, d *schema.ResourceData) error {
+	auth := expandNTLMAuth(d.Get("ntlm_auth").([]interface{}))
+	if auth == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("ntlm_auth conflicts with the Authorization request header")
+	}
+
+	client.Transport = &ntlmTransport{base: client.Transport, auth: auth}
+
+	return nil
+}
+
+// ntlmTransport performs the NTLM handshake when a server responds to a
+// request with a 401 offering NTLM. The request is then resent with a
+// NEGOTIATE message and again with the AUTHENTICATE message answering the
+// server's CHALLENGE. NTLM authenticates the connection rather than the
+// request, so the handshake relies on the base transport reusing it.
+type ntlmTransport struct {
+	base http.RoundTripper
+	auth *ntlmAuth
+}
+
+func (t *ntlmTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !offersNTLM(resp.Header) {
+		return resp, err
+	}
+	discardBody(resp)
+
+	negotiateReq, err := withAuthorization(req, ntlmAuthorization(ntlmNegotiateMessage()))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = t.base.RoundTrip(negotiateReq)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeMessage, ok := ntlmChallengeHeader(resp.Header)
+	if resp.StatusCode != http.StatusUnauthorized || !ok {
+		// The server did not continue the handshake, so its response is
+		// returned as it is.
+		return resp, nil
+	}
+	discardBody(resp)
+
+	challenge, err := parseNTLMChallenge(challengeMessage)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing NTLM challenge: %s", err)
+	}
+
+	var clientChallenge [8]byte
+	if _, err := rand.Read(clientChallenge[:]); err != nil {
+		return nil, err
+	}
+
+	authenticateReq, err := withAuthorization(req, ntlmAuthorization(ntlmAuthenticateMessage(challenge, t.auth, clientChallenge, ntlmTimestamp(time.Now()))))
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(authenticateReq)
+}
+
+// ntlmAuthorization returns the Authorization header value carrying message.
+func ntlmAuthorization(message []byte) string {
+	return "NTLM " + base64.StdEncoding.EncodeToString(message)
+}
+
+// offersNTLM reports whether a WWW-Authenticate header offers NTLM.
+func offersNTLM(header http.Header) bool {
+	for _, v := range header.Values("WWW-Authenticate") {
+		if fields := strings.Fields(v); len(fields) > 0 && strings.EqualFold(fields[0], "NTLM") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ntlmChallengeHeader returns the decoded CHALLENGE message sent in a
+// WWW-Authenticate header.
+func ntlmChallengeHeader(header http.Header) ([]byte, bool) {
+	for _, v := range header.Values("WWW-Authenticate") {
+		fields := strings.Fields(v)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], "NTLM") {
+			continue
+		}
+		if message, err := base64.StdEncoding.DecodeString(fields[1]); err == nil {
+			return message, true
+		}
+	}
+
+	return nil, false
+}
+
+// ntlmNegotiateMessage returns a NEGOTIATE message without domain or
+// workstation names.
+func ntlmNegotiateMessage() []byte {
+	message := make([]byte, 32)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 1)
+	binary.LittleEndian.PutUint32(message[12:], ntlmNegotiateFlags)
+
+	return message
+}
+
+// ntlmChallenge holds the fields of a CHALLENGE message used to answer it.
+type ntlmChallenge struct {
+	flags           uint32
+	serverChallenge [8]byte
+	targetInfo      []byte
+}
+
+func parseNTLMChallenge(message []byte) (*ntlmChallenge, error) {
+	if len(message) < 48 || !bytes.Equal(message[:8], ntlmSignature) {
+		return nil, fmt.Errorf("not an NTLM message")
+	}
+	if messageType := binary.LittleEndian.Uint32(message[8:]); messageType != 2 {
+		return nil, fmt.Errorf("expected message type 2, got %d", messageType)
+	}
+
+	c := &ntlmChallenge{
+		flags: binary.LittleEndian.Uint32(message[20:]),
+	}
+	copy(c.serverChallenge[:], message[24:32])
+
+	targetInfoLen := int(binary.LittleEndian.Uint16(message[40:]))
+	targetInfoOffset := int(binary.LittleEndian.Uint32(message[44:]))
+	if targetInfoOffset > len(message) || targetInfoLen > len(message)-targetInfoOffset {
+		return nil, fmt.Errorf("target information is out of bounds")
+	}
+	c.targetInfo = message[targetInfoOffset : targetInfoOffset+targetInfoLen]
+
+	return c, nil
+}
+
+// timestamp returns the server's timestamp from the target information, if
+// present.
+func (c *ntlmChallenge) timestamp() ([]byte, bool) {
+	info := c.targetInfo
+	for len(info) >= 4 {
+		id := binary.LittleEndian.Uint16(info)
+		length := int(binary.LittleEndian.Uint16(info[2:]))
+		if len(info) < 4+length {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return info[4:12], true
+		}
+		info = info[4+length:]
+	}
+
+	return nil, false
+}
+
+// ntlmTimestamp returns t as a FILETIME, the number of 100 nanosecond
+// intervals since January 1, 1601 UTC.
+func ntlmTimestamp(t time.Time) []byte {
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, uint64(t.UnixNano()/100+116444736000000000))
+
+	return timestamp
+}
+
+// ntowfv2 derives the NTLMv2 response key from the credentials.
+func ntowfv2(username, password, domain string) []byte {
+	h := md4.New()
+	h.Write(utf16LE(password))
+
+	return hmacMD5(h.Sum(nil), utf16LE(strings.ToUpper(username)+domain))
+}
+
+// ntlmV2Response returns the NTLMv2 NtChallengeResponse: the NTProofStr
+// followed by the client blob it was computed over.
+func ntlmV2Response(responseKey []byte, serverChallenge, clientChallenge [8]byte, timestamp, targetInfo []byte) []byte {
+	var blob bytes.Buffer
+	blob.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	blob.Write(timestamp)
+	blob.Write(clientChallenge[:])
+	blob.Write([]byte{0, 0, 0, 0})
+	blob.Write(targetInfo)
+	blob.Write([]byte{0, 0, 0, 0})
+
+	proof := hmacMD5(responseKey, append(serverChallenge[:], blob.Bytes()...))
+
+	return append(proof, blob.Bytes()...)
+}
+
+// lmV2Response returns the LMv2 LmChallengeResponse.
+func lmV2Response(responseKey []byte, serverChallenge, clientChallenge [8]byte) []byte {
+	proof := hmacMD5(responseKey, append(serverChallenge[:], clientChallenge[:]...))
+
+	return append(proof, clientChallenge[:]...)
+}
+
+// ntlmAuthenticateMessage returns the AUTHENTICATE message answering
+// challenge with an NTLMv2 response. timestamp is used unless the server sent
+// its own.
+func ntlmAuthenticateMessage(challenge *ntlmChallenge, auth *ntlmAuth, clientChallenge [8]byte, timestamp []byte) []byte {
+	responseKey := ntowfv2(auth.username, auth.password, auth.domain)
+
+	// A client sends an empty LMv2 response when the server provides a
+	// timestamp.
+	lmResponse := make([]byte, 24)
+	if serverTimestamp, ok := challenge.timestamp(); ok {
+		timestamp = serverTimestamp
+	} else {
+		lmResponse = lmV2Response(responseKey, challenge.serverChallenge, clientChallenge)
+	}
+	ntResponse := ntlmV2Response(responseKey, challenge.serverChallenge, clientChallenge, timestamp, challenge.targetInfo)
+
+	// The fixed part is followed by the payload holding each field in turn.
+	const headerLen = 64
+	fields := [][]byte{
+		lmResponse,
+		ntResponse,
+		utf16LE(auth.domain),
+		utf16LE(auth.username),
+		nil, // workstation
+		nil, // encrypted random session key
+	}
+
+	message := make([]byte, headerLen)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 3)
+
+	offset := headerLen
+	for i, field := range fields {
+		binary.LittleEndian.PutUint16(message[12+8*i:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(message[14+8*i:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(message[16+8*i:], uint32(offset))
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(message[60:], challenge.flags&ntlmNegotiateFlags)
+
+	payload := make([]byte, 0, offset-headerLen)
+	for _, field := range fields {
+		payload = append(payload, field...)
+	}
+
+	return append(message, payload...)
+}
+
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+func utf16LE(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(codes))
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[2*i:], c)
+	}
+
+	return b
+}
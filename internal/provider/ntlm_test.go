@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"testing"
+)
+
+// Values from the NTLMv2 authentication example in [MS-NLMP] section 4.2.4.
+var (
+	testNTLMAuth = &ntlmAuth{
+		username: "User",
+		password: "Password",
+		domain:   "Domain",
+	}
+	testNTLMServerChallenge = [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}
+	testNTLMClientChallenge = [8]byte{0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa, 0xaa}
+	// MsvAvNbDomainName "Domain", MsvAvNbComputerName "Server", MsvAvEOL.
+	testNTLMTargetInfo = mustDecodeHex("02000c00" + "44006f006d00610069006e00" + "01000c00" + "530065007200760065007200" + "00000000")
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestNTOWFv2(t *testing.T) {
+	expected := "0c868a403bfd7a93a3001ef22ef02e3f"
+	if actual := hex.EncodeToString(ntowfv2("User", "Password", "Domain")); actual != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestNTLMV2Response(t *testing.T) {
+	responseKey := ntowfv2("User", "Password", "Domain")
+
+	nt := ntlmV2Response(responseKey, testNTLMServerChallenge, testNTLMClientChallenge, make([]byte, 8), testNTLMTargetInfo)
+	if actual, expected := hex.EncodeToString(nt[:16]), "68cd0ab851e51c96aabc927bebef6a1c"; actual != expected {
+		t.Fatalf("expected NTProofStr %s, got %s", expected, actual)
+	}
+
+	lm := lmV2Response(responseKey, testNTLMServerChallenge, testNTLMClientChallenge)
+	if actual, expected := hex.EncodeToString(lm), "86c35097ac9cec102554764a57cccc19aaaaaaaaaaaaaaaa"; actual != expected {
+		t.Fatalf("expected LMv2 response %s, got %s", expected, actual)
+	}
+}
+
+func testNTLMChallengeMessage(targetInfo []byte) []byte {
+	message := make([]byte, 48, 48+len(targetInfo))
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 2)
+	binary.LittleEndian.PutUint32(message[20:], ntlmNegotiateFlags)
+	copy(message[24:], testNTLMServerChallenge[:])
+	binary.LittleEndian.PutUint16(message[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(message[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(message[44:], 48)
+
+	return append(message, targetInfo...)
+}
+
+func TestParseNTLMChallenge(t *testing.T) {
+	timestampInfo := mustDecodeHex("07000800" + "0090d336b734c301" + "00000000")
+
+	cases := map[string]struct {
+		Message           []byte
+		ExpectErr         bool
+		ExpectedTimestamp string
+	}{
+		"valid": {
+			Message: testNTLMChallengeMessage(testNTLMTargetInfo),
+		},
+		"timestamp": {
+			Message:           testNTLMChallengeMessage(timestampInfo),
+			ExpectedTimestamp: "0090d336b734c301",
+		},
+		"not ntlm": {
+			Message:   make([]byte, 48),
+			ExpectErr: true,
+		},
+		"negotiate message": {
+			Message:   append(ntlmNegotiateMessage(), make([]byte, 16)...),
+			ExpectErr: true,
+		},
+		"truncated": {
+			Message:   testNTLMChallengeMessage(testNTLMTargetInfo)[:60],
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := parseNTLMChallenge(tc.Message)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if c.serverChallenge != testNTLMServerChallenge {
+				t.Fatalf("expected server challenge %x, got %x", testNTLMServerChallenge, c.serverChallenge)
+			}
+			timestamp, _ := c.timestamp()
+			if actual := hex.EncodeToString(timestamp); actual != tc.ExpectedTimestamp {
+				t.Fatalf("expected timestamp %q, got %q", tc.ExpectedTimestamp, actual)
+			}
+		})
+	}
+}
+
+func TestNTLMAuthenticateMessage(t *testing.T) {
+	challenge, err := parseNTLMChallenge(testNTLMChallengeMessage(testNTLMTargetInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := ntlmAuthenticateMessage(challenge, testNTLMAuth, testNTLMClientChallenge, make([]byte, 8))
+
+	field := func(offset int) []byte {
+		length := binary.LittleEndian.Uint16(message[offset:])
+		start := binary.LittleEndian.Uint32(message[offset+4:])
+		return message[start : start+uint32(length)]
+	}
+
+	if messageType := binary.LittleEndian.Uint32(message[8:]); messageType != 3 {
+		t.Fatalf("expected message type 3, got %d", messageType)
+	}
+	if actual, expected := hex.EncodeToString(field(20)[:16]), "68cd0ab851e51c96aabc927bebef6a1c"; actual != expected {
+		t.Fatalf("expected NTProofStr %s, got %s", expected, actual)
+	}
+	if actual, expected := string(field(28)), string(utf16LE("Domain")); actual != expected {
+		t.Fatalf("expected domain %q, got %q", expected, actual)
+	}
+	if actual, expected := string(field(36)), string(utf16LE("User")); actual != expected {
+		t.Fatalf("expected user name %q, got %q", expected, actual)
+	}
+}
+
+func TestNTLMChallengeHeader(t *testing.T) {
+	message := testNTLMChallengeMessage(testNTLMTargetInfo)
+
+	cases := map[string]struct {
+		Values   []string
+		Offers   bool
+		Expected []byte
+	}{
+		"none": {},
+		"offer": {
+			Values: []string{"Negotiate", "NTLM"},
+			Offers: true,
+		},
+		"challenge": {
+			Values:   []string{"NTLM " + base64.StdEncoding.EncodeToString(message)},
+			Offers:   true,
+			Expected: message,
+		},
+		"basic": {
+			Values: []string{`Basic realm="test"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tc.Values {
+				header.Add("WWW-Authenticate", v)
+			}
+			if actual := offersNTLM(header); actual != tc.Offers {
+				t.Fatalf("expected offersNTLM %t, got %t", tc.Offers, actual)
+			}
+			actual, ok := ntlmChallengeHeader(header)
+			if ok != (tc.Expected != nil) || string(actual) != string(tc.Expected) {
+				t.Fatalf("expected challenge %x, got %x", tc.Expected, actual)
+			}
+		})
+	}
+}
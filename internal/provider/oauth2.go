@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// clientCredentialsConfig holds the settings of the oauth2_client_credentials
+// block.
+type clientCredentialsConfig struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+}
+
+func expandClientCredentialsConfig(v []interface{}) *clientCredentialsConfig {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	c := &clientCredentialsConfig{
+		tokenURL:     m["token_url"].(string),
+		clientID:     m["client_id"].(string),
+		clientSecret: m["client_secret"].(string),
+	}
+	for _, scope := range m["scopes"].([]interface{}) {
+		c.scopes = append(c.scopes, scope.(string))
+	}
+
+	return c
+}
+
+// applyOAuth2ClientCredentials obtains a token from the token endpoint of the
+// oauth2_client_credentials block, if configured, and sets it as the
+// request's Authorization header. Tokens are shared through the provider, so
+// data sources with the same block reuse a token until it expires.
+func applyOAuth2ClientCredentials(config *providerConfig, client *http.Client, req *http.Request, d *schema.ResourceData) error {
+	c := expandClientCredentialsConfig(d.Get("oauth2_client_credentials").([]interface{}))
+	if c == nil {
+		return nil
+	}
+
+	if hasRequestHeader(d, "Authorization") {
+		return fmt.Errorf("oauth2_client_credentials conflicts with the Authorization request header")
+	}
+
+	token, err := config.oauth2TokenSource(c, transportKey(d), client).Token()
+	if err != nil {
+		return fmt.Errorf("Error obtaining OAuth2 token: %s", err)
+	}
+
+	token.SetAuthHeader(req)
+
+	return nil
+}
+
+// clientCredentialsKey identifies the token sources shared by data sources.
+// The secret and scopes are part of it as they change the token issued, and
+// the transport key as the token requests are sent with the data source's
+// TLS and proxy settings.
+type clientCredentialsKey struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       string
+	transport    string
+}
+
+// oauth2TokenSource returns the token source shared by data sources with the
+// settings creds and the transport identified by transport, creating it if
+// needed. A new source sends its token requests with client, so they use the
+// timeout of the data source that first needed a token.
+func (c *providerConfig) oauth2TokenSource(creds *clientCredentialsConfig, transport string, client *http.Client) oauth2.TokenSource {
+	key := clientCredentialsKey{
+		tokenURL:     creds.tokenURL,
+		clientID:     creds.clientID,
+		clientSecret: creds.clientSecret,
+		scopes:       strings.Join(creds.scopes, " "),
+		transport:    transport,
+	}
+
+	c.oauth2Mu.Lock()
+	defer c.oauth2Mu.Unlock()
+
+	if c.oauth2TokenSources == nil {
+		c.oauth2TokenSources = make(map[clientCredentialsKey]oauth2.TokenSource)
+	}
+
+	ts, ok := c.oauth2TokenSources[key]
+	if !ok {
+		config := &clientcredentials.Config{
+			ClientID:     creds.clientID,
+			ClientSecret: creds.clientSecret,
+			TokenURL:     creds.tokenURL,
+			Scopes:       creds.scopes,
+			AuthStyle:    oauth2.AuthStyleInHeader,
+		}
+
+		// The source outlives the read that created it, so its requests are
+		// bounded by the client's timeout rather than by the read's context.
+		ts = config.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client))
+		c.oauth2TokenSources[key] = ts
+	}
+
+	return ts
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestOAuth2TokenSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if user, pass, _ := r.BasicAuth(); user != "client" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"bad secret"}`))
+			return
+		}
+
+		if v := r.PostFormValue("grant_type"); v != "client_credentials" {
+			t.Errorf("expected grant_type client_credentials, got %q", v)
+		}
+		if v := r.PostFormValue("scope"); v != "read write" {
+			t.Errorf("expected scope %q, got %q", "read write", v)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":3600}`))
+	}))
+
+	defer server.Close()
+
+	cases := map[string]struct {
+		ClientSecret string
+		ExpectErr    string
+	}{
+		"success": {
+			ClientSecret: "secret",
+		},
+		"invalid client": {
+			ClientSecret: "wrong",
+			ExpectErr:    "bad secret",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			config := &providerConfig{}
+			creds := &clientCredentialsConfig{
+				tokenURL:     server.URL,
+				clientID:     "client",
+				clientSecret: tc.ClientSecret,
+				scopes:       []string{"read", "write"},
+			}
+
+			token, err := config.oauth2TokenSource(creds, "", server.Client()).Token()
+			if tc.ExpectErr != "" {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				if !strings.Contains(err.Error(), tc.ExpectErr) {
+					t.Fatalf("expected error to contain %q, got %q", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if token.AccessToken != "abc" {
+				t.Fatalf("expected token %q, got %q", "abc", token.AccessToken)
+			}
+			if token.Type() != "Bearer" {
+				t.Fatalf("expected token type Bearer, got %q", token.Type())
+			}
+			if token.Expiry.IsZero() {
+				t.Fatal("expected token to expire")
+			}
+		})
+	}
+}
+
+func TestOAuth2ClientCredentialsTokenReused(t *testing.T) {
+	var tokenRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":3600}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+
+	defer server.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	for _, path := range []string{"/first", "/second"} {
+		d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+			"url": server.URL + path,
+			"oauth2_client_credentials": []interface{}{
+				map[string]interface{}{
+					"token_url":     server.URL + "/oauth2/token",
+					"client_id":     "client",
+					"client_secret": "secret",
+				},
+			},
+		})
+
+		if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+			t.Fatalf("unexpected error reading %s: %v", path, diags)
+		}
+
+		if body := d.Get("body").(string); body != "Bearer abc" {
+			t.Fatalf("expected body %q for %s, got %q", "Bearer abc", path, body)
+		}
+	}
+
+	if n := atomic.LoadInt32(&tokenRequests); n != 1 {
+		t.Fatalf("expected 1 token request, got %d", n)
+	}
+}
+
+// Data sources with different connection settings do not share a token
+// source, so each sends its token requests with its own transport.
+func TestOAuth2ClientCredentialsTokenPerTransport(t *testing.T) {
+	var tokenRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":3600}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	for _, disableKeepAlives := range []bool{false, true} {
+		d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+			"url":                 server.URL,
+			"disable_keep_alives": disableKeepAlives,
+			"oauth2_client_credentials": []interface{}{
+				map[string]interface{}{
+					"token_url":     server.URL + "/oauth2/token",
+					"client_id":     "client",
+					"client_secret": "secret",
+				},
+			},
+		})
+
+		if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+			t.Fatalf("unexpected error reading with disable_keep_alives %t: %v", disableKeepAlives, diags)
+		}
+	}
+
+	if n := atomic.LoadInt32(&tokenRequests); n != 2 {
+		t.Fatalf("expected 2 token requests, got %d", n)
+	}
+}
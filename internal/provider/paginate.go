@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// paginateModeConcatJSONArrays combines pages that are JSON arrays into a
+	// single array of their elements.
+	paginateModeConcatJSONArrays = "concat-json-arrays"
+	// paginateModeCollectBodies combines pages into a JSON array of their
+	// bodies as strings.
+	paginateModeCollectBodies = "collect-bodies"
+)
+
+// paginateModes are the ways pages can be combined.
+var paginateModes = []string{
+	paginateModeConcatJSONArrays,
+	paginateModeCollectBodies,
+}
+
+// paginateConfig holds the settings of the paginate block.
+type paginateConfig struct {
+	// maxPages is the number of pages fetched at most, including the first.
+	maxPages int
+	mode     string
+}
+
+func expandPaginateConfig(v []interface{}) *paginateConfig {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return &paginateConfig{
+		maxPages: m["max_pages"].(int),
+		mode:     m["mode"].(string),
+	}
+}
+
+// fetchPages follows the next links of the Link headers from the response of
+// the first page, whose body is first, until there are none left or
+// maxPages pages are fetched. Each page is requested like the first one, with
+// prepare called on the request before it is sent. It returns the bodies of
+// all pages and whether a next link was left unfollowed.
+func fetchPages(ctx context.Context, client *http.Client, req *http.Request, resp *http.Response, first []byte, p *paginateConfig, retry retryConfig, expectedStatusCodes []interface{}, limit int64, prepare func(*http.Request) error) ([][]byte, bool, error) {
+	pages := [][]byte{first}
+
+	for {
+		link := nextLink(resp.Header)
+		if link == "" {
+			return pages, false, nil
+		}
+		if len(pages) >= p.maxPages {
+			return pages, true, nil
+		}
+
+		// Links may be relative to the URL of the page they were found on.
+		u, err := resp.Request.URL.Parse(link)
+		if err != nil {
+			return nil, false, fmt.Errorf("Error parsing the next link of page %d: %s", len(pages), err)
+		}
+
+		pageReq, err := withURL(req, u)
+		if err != nil {
+			return nil, false, fmt.Errorf("Error creating request for page %d: %s", len(pages)+1, err)
+		}
+		if err := prepare(pageReq); err != nil {
+			return nil, false, err
+		}
+
+		var attempts int
+		resp, attempts, err = doRequestWithRetry(ctx, client, pageReq, retry)
+		if err != nil {
+			return nil, false, fmt.Errorf("Error making request for page %d: %s%s", len(pages)+1, err, attemptsSuffix(attempts))
+		}
+
+		if !isStatusCodeExpected(resp.StatusCode, expectedStatusCodes) {
+			discardBody(resp)
+			return nil, false, fmt.Errorf("HTTP request error for page %d. Response code: %d%s", len(pages)+1, resp.StatusCode, attemptsSuffix(attempts))
+		}
+
+		body, err := readResponseBody(resp.Body, limit)
+		resp.Body.Close()
+		if err != nil {
+			return nil, false, err
+		}
+
+		pages = append(pages, body)
+	}
+}
+
+// combine joins the bodies of the pages into one according to the mode.
+func (p *paginateConfig) combine(pages [][]byte) ([]byte, error) {
+	switch p.mode {
+	case paginateModeConcatJSONArrays:
+		elements := []json.RawMessage{}
+		for i, page := range pages {
+			var v []json.RawMessage
+			if err := json.Unmarshal(page, &v); err != nil {
+				return nil, fmt.Errorf("page %d is not a JSON array: %s", i+1, err)
+			}
+			elements = append(elements, v...)
+		}
+		return json.Marshal(elements)
+	case paginateModeCollectBodies:
+		bodies := make([]string, len(pages))
+		for i, page := range pages {
+			bodies[i] = string(page)
+		}
+		return json.Marshal(bodies)
+	default:
+		return nil, fmt.Errorf("unsupported mode %q", p.mode)
+	}
+}
+
+// nextLink returns the target of the first link with the relation type next
+// in the Link headers, as described in RFC 8288, or an empty string if there
+// is none.
+func nextLink(header http.Header) string {
+	for _, v := range header.Values("Link") {
+		for {
+			start := strings.IndexByte(v, '<')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(v[start:], '>')
+			if end < 0 {
+				break
+			}
+			end += start
+
+			target := v[start+1 : end]
+			v = v[end+1:]
+
+			// The parameters of a link run up to the start of the next one.
+			params := v
+			if next := strings.IndexByte(v, '<'); next >= 0 {
+				params = v[:next]
+			}
+
+			for _, param := range strings.Split(params, ";") {
+				kv := strings.SplitN(param, "=", 2)
+				if len(kv) != 2 || !strings.EqualFold(strings.TrimSpace(kv[0]), "rel") {
+					continue
+				}
+
+				// The relation types are a space separated list.
+				value := strings.Trim(strings.TrimRight(strings.TrimSpace(kv[1]), ", "), `"`)
+				for _, rel := range strings.Fields(value) {
+					if strings.EqualFold(rel, "next") {
+						return target
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
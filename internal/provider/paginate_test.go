@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNextLink(t *testing.T) {
+	cases := map[string]struct {
+		Links    []string
+		Expected string
+	}{
+		"none": {},
+		"next": {
+			Links:    []string{`<https://example.com/items?page=2>; rel="next"`},
+			Expected: "https://example.com/items?page=2",
+		},
+		"unquoted": {
+			Links:    []string{`<items?page=2>; rel=next`},
+			Expected: "items?page=2",
+		},
+		"among others": {
+			Links:    []string{`<https://example.com/items?page=1>; rel="first", <https://example.com/items?page=3>; rel="next", <https://example.com/items?page=9>; rel="last"`},
+			Expected: "https://example.com/items?page=3",
+		},
+		"several headers": {
+			Links: []string{
+				`<https://example.com/items?page=1>; rel="prev"`,
+				`<https://example.com/items?page=3>; title="Next"; rel="next"`,
+			},
+			Expected: "https://example.com/items?page=3",
+		},
+		"relation list": {
+			Links:    []string{`<https://example.com/items?page=2>; rel="Next alternate"`},
+			Expected: "https://example.com/items?page=2",
+		},
+		"no next": {
+			Links: []string{`<https://example.com/items?page=1>; rel="prev", <https://example.com/items?page=9>; rel="last"`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tc.Links {
+				header.Add("Link", v)
+			}
+
+			if actual := nextLink(header); actual != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestPaginateConfigCombine(t *testing.T) {
+	cases := map[string]struct {
+		Mode      string
+		Pages     []string
+		Expected  string
+		ExpectErr bool
+	}{
+		"concat json arrays": {
+			Mode:     paginateModeConcatJSONArrays,
+			Pages:    []string{`[1, {"a": 2}]`, `[]`, `["b"]`},
+			Expected: `[1,{"a":2},"b"]`,
+		},
+		"concat empty arrays": {
+			Mode:     paginateModeConcatJSONArrays,
+			Pages:    []string{`[]`},
+			Expected: `[]`,
+		},
+		"concat not an array": {
+			Mode:      paginateModeConcatJSONArrays,
+			Pages:     []string{`[1]`, `{"items": [2]}`},
+			ExpectErr: true,
+		},
+		"collect bodies": {
+			Mode:     paginateModeCollectBodies,
+			Pages:    []string{`first`, `"second"`},
+			Expected: `["first","\"second\""]`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pages := make([][]byte, len(tc.Pages))
+			for i, page := range tc.Pages {
+				pages[i] = []byte(page)
+			}
+
+			actual, err := (&paginateConfig{mode: tc.Mode}).combine(pages)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(actual) != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
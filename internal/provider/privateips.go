@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// privateNetwork is a range of addresses refused by block_private_ips.
+type privateNetwork struct {
+	network *net.IPNet
+	kind    string
+}
+
+// privateNetworks are the loopback, private and link-local ranges, along with
+// the unspecified addresses, which reach the local host.
+var privateNetworks = parsePrivateNetworks(map[string]string{
+	"127.0.0.0/8":    "loopback",
+	"::1/128":        "loopback",
+	"10.0.0.0/8":     "private",
+	"172.16.0.0/12":  "private",
+	"192.168.0.0/16": "private",
+	"fc00::/7":       "private",
+	"169.254.0.0/16": "link-local",
+	"fe80::/10":      "link-local",
+	"0.0.0.0/8":      "unspecified",
+	"::/128":         "unspecified",
+})
+
+func parsePrivateNetworks(cidrs map[string]string) []privateNetwork {
+	var networks []privateNetwork
+	for cidr, kind := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, privateNetwork{network: network, kind: kind})
+	}
+
+	return networks
+}
+
+// privateIPError is returned for a connection refused by block_private_ips.
+type privateIPError struct {
+	ip   net.IP
+	kind string
+}
+
+func (e *privateIPError) Error() string {
+	return fmt.Sprintf("connection to %s is blocked by block_private_ips as it is a %s address", e.ip, e.kind)
+}
+
+// blockPrivateIPs returns a net.Dialer Control function that refuses
+// connections to private addresses outside of allowed. It is called with the
+// resolved address of every connection, including those for redirects, so a
+// host name cannot be made to resolve to a private address after it is
+// checked.
+func blockPrivateIPs(allowed []*net.IPNet) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, _ syscall.RawConn) error {
+		// Unix domain sockets have no IP address.
+		if !strings.HasPrefix(network, "tcp") && !strings.HasPrefix(network, "udp") {
+			return nil
+		}
+
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return fmt.Errorf("cannot parse dialled address %q", address)
+		}
+
+		for _, network := range allowed {
+			if network.Contains(ip) {
+				return nil
+			}
+		}
+
+		for _, private := range privateNetworks {
+			if private.network.Contains(ip) {
+				return &privateIPError{ip: ip, kind: private.kind}
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestBlockPrivateIPs(t *testing.T) {
+	_, allowed, err := net.ParseCIDR("10.1.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		Network    string
+		Address    string
+		ExpectKind string
+	}{
+		"public":             {Network: "tcp4", Address: "93.184.216.34:443"},
+		"public ipv6":        {Network: "tcp6", Address: "[2606:2800:220:1::1]:443"},
+		"loopback":           {Network: "tcp4", Address: "127.0.0.1:80", ExpectKind: "loopback"},
+		"loopback ipv6":      {Network: "tcp6", Address: "[::1]:80", ExpectKind: "loopback"},
+		"ipv4-mapped":        {Network: "tcp6", Address: "[::ffff:127.0.0.1]:80", ExpectKind: "loopback"},
+		"rfc1918 10/8":       {Network: "tcp4", Address: "10.2.3.4:80", ExpectKind: "private"},
+		"rfc1918 172.16/12":  {Network: "tcp4", Address: "172.31.0.1:80", ExpectKind: "private"},
+		"outside 172.16/12":  {Network: "tcp4", Address: "172.32.0.1:80"},
+		"rfc1918 192.168/16": {Network: "tcp4", Address: "192.168.1.1:80", ExpectKind: "private"},
+		"unique local":       {Network: "tcp6", Address: "[fd00::1]:80", ExpectKind: "private"},
+		"link-local":         {Network: "tcp4", Address: "169.254.169.254:80", ExpectKind: "link-local"},
+		"link-local ipv6":    {Network: "tcp6", Address: "[fe80::1]:80", ExpectKind: "link-local"},
+		"unspecified":        {Network: "tcp4", Address: "0.0.0.0:80", ExpectKind: "unspecified"},
+		"allowed":            {Network: "tcp4", Address: "10.1.2.3:80"},
+		"unix domain socket": {Network: "unix", Address: "/var/run/docker.sock"},
+	}
+
+	control := blockPrivateIPs([]*net.IPNet{allowed})
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := control(tc.Network, tc.Address, nil)
+			if tc.ExpectKind == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			var privateIPErr *privateIPError
+			if !errors.As(err, &privateIPErr) {
+				t.Fatalf("expected a private IP error, got %v", err)
+			}
+			if privateIPErr.kind != tc.ExpectKind {
+				t.Fatalf("expected a %s address, got %s", tc.ExpectKind, privateIPErr.kind)
+			}
+		})
+	}
+}
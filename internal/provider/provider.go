@@ -1,17 +1,286 @@
 package provider
 
 import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
+// version is the provider version reported in the default User-Agent header.
+// Release builds set it with -ldflags "-X <package path>.version=<version>".
+var version = "dev"
+
+// defaultUserAgent is the User-Agent header sent unless one is configured.
+func defaultUserAgent() string {
+	return "terraform-provider-http/" + version
+}
+
 func New() *schema.Provider {
 	return &schema.Provider{
-		Schema: map[string]*schema.Schema{},
+		Schema: map[string]*schema.Schema{
+			"default_headers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Request headers sent by every data source. Headers set in a data source's request_headers take precedence.",
+			},
+
+			"timeout_ms": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "The default request timeout in milliseconds, used when a data source does not set request_timeout_ms.",
+			},
+
+			"ca_cert_pem": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "One or more PEM encoded CA certificates used to verify server certificates, used when a data source does not set ca_cert_pem.",
+			},
+
+			"cache": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Cache responses so that identical requests from several data sources are sent once.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ttl_ms": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "How long a response is cached for, in milliseconds.",
+						},
+					},
+				},
+			},
+
+			"rate_limit": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Limit the rate of requests sent by all data sources and resources.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"requests_per_second": {
+							Type:         schema.TypeFloat,
+							Required:     true,
+							ValidateFunc: validateFloatGreaterThan(0),
+							Description:  "The sustained number of requests allowed per second.",
+						},
+
+						"burst": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      1,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "The number of requests that may be sent at once before the rate applies.",
+						},
+					},
+				},
+			},
+
+			"allowed_hosts": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateHostGlob(),
+				},
+				Description: "Host name globs, such as *.example.com, that requests may be sent to. Requests and redirects to other hosts fail without being sent.",
+			},
+
+			"max_idle_conns": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validateIntAtLeast(0),
+				Description:  "The maximum number of idle connections kept open for reuse across all hosts, or 0 for no limit.",
+			},
+
+			"max_idle_conns_per_host": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      http.DefaultMaxIdleConnsPerHost,
+				ValidateFunc: validateIntAtLeast(1),
+				Description:  "The maximum number of idle connections kept open for reuse to each host.",
+			},
+
+			"circuit_breaker": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Fail requests to a host at once after repeated failures, instead of waiting through retries and timeouts.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"failure_threshold": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      5,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "The number of consecutive failed requests to a host that open its circuit.",
+						},
+
+						"cooldown_ms": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      30000,
+							ValidateFunc: validateIntAtLeast(1),
+							Description:  "How long requests to a host fail at once before one is sent to probe it, in milliseconds.",
+						},
+					},
+				},
+			},
+		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"http": dataSource(),
+			"http":      dataSource(),
+			"http_head": headDataSource(),
+		},
+
+		ResourcesMap: map[string]*schema.Resource{
+			"http_request": requestResource(),
 		},
 
-		ResourcesMap: map[string]*schema.Resource{},
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+// providerConfig holds the provider-level defaults shared by all data sources.
+type providerConfig struct {
+	defaultHeaders map[string]string
+	timeoutMs      int
+	caCertPEM      string
+	// limiter is nil unless rate_limit is set.
+	limiter *rate.Limiter
+	// cache is nil unless cache is set.
+	cache *responseCache
+	// breaker is nil unless circuit_breaker is set.
+	breaker *circuitBreaker
+	// allowlist is nil unless allowed_hosts is set.
+	allowlist *hostAllowlist
+
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	// transports are the HTTP transports shared by data sources and
+	// resources, so that their idle connections are reused by later requests.
+	transportsMu sync.Mutex
+	transports   map[string]*http.Transport
+
+	// oauth2TokenSources are the token sources of oauth2_client_credentials,
+	// shared by data sources so that tokens are reused until they expire.
+	oauth2Mu           sync.Mutex
+	oauth2TokenSources map[clientCredentialsKey]oauth2.TokenSource
+}
+
+// transport wraps tr in the provider's rate limiter, circuit breaker and
+// host allowlist, if configured.
+func (c *providerConfig) transport(tr http.RoundTripper) http.RoundTripper {
+	if c.limiter != nil {
+		tr = &rateLimitedTransport{base: tr, limiter: c.limiter}
+	}
+
+	// Requests to a host whose circuit is open fail without waiting for the
+	// rate limiter.
+	if c.breaker != nil {
+		tr = &circuitBreakerTransport{base: tr, breaker: c.breaker}
+	}
+
+	// Requests to hosts that are not allowed are never sent, so they do not
+	// count towards the rate limit or the circuit breaker.
+	if c.allowlist != nil {
+		tr = &allowedHostsTransport{base: tr, allowlist: c.allowlist}
 	}
+
+	return tr
+}
+
+// sharedTransport returns the transport stored under key, calling build to
+// create it on first use. The provider's connection pool settings are applied
+// to the transports it creates.
+func (c *providerConfig) sharedTransport(key string, build func() (*http.Transport, error)) (*http.Transport, error) {
+	c.transportsMu.Lock()
+	defer c.transportsMu.Unlock()
+
+	if tr, ok := c.transports[key]; ok {
+		return tr, nil
+	}
+
+	tr, err := build()
+	if err != nil {
+		return nil, err
+	}
+	tr.MaxIdleConns = c.maxIdleConns
+	tr.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+
+	if c.transports == nil {
+		c.transports = make(map[string]*http.Transport)
+	}
+	c.transports[key] = tr
+
+	return tr, nil
+}
+
+// defaultTransport returns the transport built from the provider-level
+// defaults alone, shared by the http_head data source and the http_request
+// resource.
+func (c *providerConfig) defaultTransport() (*http.Transport, error) {
+	return c.sharedTransport("", func() (*http.Transport, error) {
+		tlsConfig, err := c.defaultTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		}, nil
+	})
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	defaultHeaders := make(map[string]string)
+	for name, value := range d.Get("default_headers").(map[string]interface{}) {
+		defaultHeaders[name] = value.(string)
+	}
+
+	config := &providerConfig{
+		defaultHeaders: defaultHeaders,
+		timeoutMs:      d.Get("timeout_ms").(int),
+		caCertPEM:      d.Get("ca_cert_pem").(string),
+
+		maxIdleConns:        d.Get("max_idle_conns").(int),
+		maxIdleConnsPerHost: d.Get("max_idle_conns_per_host").(int),
+	}
+
+	if v := d.Get("rate_limit").([]interface{}); len(v) > 0 && v[0] != nil {
+		m := v[0].(map[string]interface{})
+		config.limiter = rate.NewLimiter(rate.Limit(m["requests_per_second"].(float64)), m["burst"].(int))
+	}
+
+	if v := d.Get("cache").([]interface{}); len(v) > 0 && v[0] != nil {
+		m := v[0].(map[string]interface{})
+		config.cache = newResponseCache(time.Duration(m["ttl_ms"].(int)) * time.Millisecond)
+	}
+
+	if v := d.Get("allowed_hosts").([]interface{}); len(v) > 0 {
+		config.allowlist = newHostAllowlist(v)
+	}
+
+	if v := d.Get("circuit_breaker").([]interface{}); len(v) > 0 && v[0] != nil {
+		m := v[0].(map[string]interface{})
+		config.breaker = newCircuitBreaker(m["failure_threshold"].(int), time.Duration(m["cooldown_ms"].(int))*time.Millisecond)
+	}
+
+	return config, nil
 }
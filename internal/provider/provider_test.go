@@ -1,9 +1,22 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
 var testProviders = map[string]*schema.Provider{
@@ -15,3 +28,610 @@ func TestProvider(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 }
+
+const testProviderConfig_defaultHeaders = `
+provider "http" {
+  default_headers = {
+    "Authorization" = "Zm9vOmJhcg=="
+  }
+}
+
+data "http" "http_test" {
+  url = "%s/restricted/meta_%d.txt"
+
+  request_headers = {
+%s
+  }
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestProvider_defaultHeaders(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_defaultHeaders, testHttpMock.server.URL, 200, ""),
+				Check: func(s *terraform.State) error {
+					_, ok := s.RootModule().Resources["data.http.http_test"]
+					if !ok {
+						return fmt.Errorf("missing data resource")
+					}
+
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_defaultHeadersOverridden(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testProviderConfig_defaultHeaders, testHttpMock.server.URL, 200, `"authorization" = "wrong"`),
+				ExpectError: regexp.MustCompile("HTTP request error. Response code: 403"),
+			},
+		},
+	})
+}
+
+const testProviderConfig_circuitBreaker = `
+provider "http" {
+  circuit_breaker {
+    failure_threshold = 2
+    cooldown_ms       = 60000
+  }
+}
+
+data "http" "http_test" {
+  url = "%s/unavailable/meta_%d.txt"
+
+  retry {
+    attempts     = 5
+    min_delay_ms = 10
+    max_delay_ms = 50
+  }
+}
+`
+
+func TestProvider_circuitBreaker(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	// The third attempt fails without being sent and stops the retries.
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testProviderConfig_circuitBreaker, testHttpMock.server.URL, 200),
+				ExpectError: regexp.MustCompile(`Circuit breaker is open for 127\.0\.0\.1:\d+ \(3 attempts made\)`),
+			},
+		},
+	})
+}
+
+const testProviderConfig_allowedHosts = `
+provider "http" {
+  allowed_hosts = ["127.0.0.1"]
+}
+
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestProvider_allowedHosts(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_allowedHosts, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config:      fmt.Sprintf(testProviderConfig_allowedHosts, strings.Replace(testHttpMock.server.URL, "127.0.0.1", "localhost", 1), 200),
+				ExpectError: regexp.MustCompile(`Host localhost is not allowed`),
+			},
+		},
+	})
+}
+
+const testProviderConfig_sentRequestHeaders = `
+provider "http" {
+  default_headers = {
+    "X-Default"    = "provider"
+    "X-Overridden" = "provider"
+  }
+}
+
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+
+  request_headers = {
+    "X-Overridden" = "data source"
+  }
+
+  user_agent   = "test"
+  bearer_token = "secret"
+}
+
+output "sent_request_headers" {
+  value = jsonencode(data.http.http_test.sent_request_headers)
+}
+`
+
+func TestProvider_sentRequestHeaders(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_sentRequestHeaders, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					var headers map[string]string
+					if err := json.Unmarshal([]byte(outputs["sent_request_headers"].Value.(string)), &headers); err != nil {
+						return err
+					}
+
+					expected := map[string]string{
+						"Authorization": "REDACTED",
+						"User-Agent":    "test",
+						"X-Default":     "provider",
+						"X-Overridden":  "data source",
+					}
+					if !reflect.DeepEqual(headers, expected) {
+						return fmt.Errorf("'sent_request_headers' output is %v; want %v", headers, expected)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testProviderConfig_timeout = `
+provider "http" {
+  timeout_ms = 10
+}
+
+data "http" "http_test" {
+  url = "%s/slow/meta_%d.txt"
+
+  request_timeout_ms = %d
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestProvider_timeout(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config:      fmt.Sprintf(testProviderConfig_timeout, testHttpMock.server.URL, 200, 0),
+				ExpectError: regexp.MustCompile("HTTP request timed out after 10ms"),
+			},
+		},
+	})
+}
+
+const testProviderConfig_rateLimit = `
+provider "http" {
+  rate_limit {
+    requests_per_second = 4
+  }
+}
+
+data "http" "http_test" {
+  count = 3
+
+  url = "%s/rate/meta_%d.txt"
+}
+
+output "elapsed_ms" {
+  value = max([for d in data.http.http_test : tonumber(d.body)]...)
+}
+`
+
+func TestProvider_rateLimit(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_rateLimit, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					// At 4 requests per second the third request is sent 500ms
+					// after the first. Allow for timer jitter.
+					elapsed, err := strconv.Atoi(outputs["elapsed_ms"].Value.(string))
+					if err != nil {
+						return err
+					}
+					if elapsed < 450 {
+						return fmt.Errorf("'elapsed_ms' output is %d; want at least 500", elapsed)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testProviderConfig_cache = `
+provider "http" {
+  cache {
+    ttl_ms = 60000
+  }
+}
+
+data "http" "http_test" {
+  count = 2
+
+  url = "%s/count/%smeta_%d.txt"
+}
+
+output "bodies" {
+  value = join(",", sort(data.http.http_test[*].body))
+}
+
+output "from_cache" {
+  value = join(",", sort([for d in data.http.http_test : tostring(d.from_cache)]))
+}
+`
+
+func TestProvider_cache(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_cache, testHttpMock.server.URL, "", 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					// The server counts requests, so a second request would
+					// return 2.
+					if outputs["bodies"].Value != "1,1" {
+						return fmt.Errorf(
+							`'bodies' output is %s; want '1,1'`,
+							outputs["bodies"].Value,
+						)
+					}
+
+					if outputs["from_cache"].Value != "false,true" {
+						return fmt.Errorf(
+							`'from_cache' output is %s; want 'false,true'`,
+							outputs["from_cache"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_cacheKey(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/final", http.StatusFound)
+			return
+		}
+		w.Write([]byte(strconv.Itoa(int(n))))
+	}))
+	defer server.Close()
+
+	cases := map[string]struct {
+		First           map[string]interface{}
+		Second          map[string]interface{}
+		ExpectFromCache bool
+		ExpectStatus    int
+		ExpectError     string
+	}{
+		"same arguments": {
+			First:           map[string]interface{}{"url": server.URL + "/final"},
+			Second:          map[string]interface{}{"url": server.URL + "/final"},
+			ExpectFromCache: true,
+			ExpectStatus:    http.StatusOK,
+		},
+		// The followed redirect is not served to a request that returns the
+		// redirect response itself.
+		"follow_redirects": {
+			First:        map[string]interface{}{"url": server.URL + "/redirect"},
+			Second:       map[string]interface{}{"url": server.URL + "/redirect", "follow_redirects": false, "expected_status_codes": []interface{}{302}},
+			ExpectStatus: http.StatusFound,
+		},
+		"transport argument": {
+			First:        map[string]interface{}{"url": server.URL + "/final"},
+			Second:       map[string]interface{}{"url": server.URL + "/final", "disable_http2": true},
+			ExpectStatus: http.StatusOK,
+		},
+		// A response fetched without the protection is not served to a
+		// request that would have been refused.
+		"block_private_ips": {
+			First:       map[string]interface{}{"url": server.URL + "/final"},
+			Second:      map[string]interface{}{"url": server.URL + "/final", "block_private_ips": true},
+			ExpectError: "Connection to loopback address 127.0.0.1 is blocked",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New()
+			if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+				"cache": []interface{}{map[string]interface{}{"ttl_ms": 60000}},
+			})); diags.HasError() {
+				t.Fatalf("unexpected error configuring provider: %v", diags)
+			}
+
+			first := schema.TestResourceDataRaw(t, dataSource().Schema, tc.First)
+			if diags := dataSourceRead(context.Background(), first, p.Meta()); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			second := schema.TestResourceDataRaw(t, dataSource().Schema, tc.Second)
+			diags := dataSourceRead(context.Background(), second, p.Meta())
+			if tc.ExpectError != "" {
+				if !diags.HasError() || diags[0].Summary != tc.ExpectError {
+					t.Fatalf("expected error %q, got %v", tc.ExpectError, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if fromCache := second.Get("from_cache").(bool); fromCache != tc.ExpectFromCache {
+				t.Fatalf("expected from_cache %t, got %t", tc.ExpectFromCache, fromCache)
+			}
+			if status := second.Get("status_code").(int); status != tc.ExpectStatus {
+				t.Fatalf("expected status %d, got %d", tc.ExpectStatus, status)
+			}
+		})
+	}
+}
+
+func TestProvider_cacheNoStore(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_cache, testHttpMock.server.URL, "no-store/", 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["bodies"].Value != "1,2" {
+						return fmt.Errorf(
+							`'bodies' output is %s; want '1,2'`,
+							outputs["bodies"].Value,
+						)
+					}
+
+					if outputs["from_cache"].Value != "false,false" {
+						return fmt.Errorf(
+							`'from_cache' output is %s; want 'false,false'`,
+							outputs["from_cache"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_timeoutOverridden(t *testing.T) {
+	testHttpMock := setUpMockHttpServer()
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_timeout, testHttpMock.server.URL, 200, 5000),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+const testProviderConfig_caCert = `
+provider "http" {
+  ca_cert_pem = <<EOT
+%s
+EOT
+}
+
+data "http" "http_test" {
+  url = "%s/meta_%d.txt"
+}
+
+output "body" {
+  value = data.http.http_test.body
+}
+`
+
+func TestProvider_caCert(t *testing.T) {
+	testHttpMock, ca := setUpMockHttpsServerWithCA(t)
+
+	defer testHttpMock.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testProviderConfig_caCert, ca.certPEM, testHttpMock.server.URL, 200),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["body"].Value != "1.0.0,GET" {
+						return fmt.Errorf(
+							`'body' output is %s; want '1.0.0,GET'`,
+							outputs["body"].Value,
+						)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProvider_sharedTransport(t *testing.T) {
+	var connections int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("1.0.0"))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+
+	defer server.Close()
+
+	p := New()
+	if diags := p.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{
+		"max_idle_conns_per_host": 4,
+	})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	reads := []struct {
+		Raw               map[string]interface{}
+		ExpectConnections int32
+	}{
+		{
+			Raw:               map[string]interface{}{"url": server.URL + "/first"},
+			ExpectConnections: 1,
+		},
+		// Arguments other than the connection related ones do not stop the
+		// connection from being reused.
+		{
+			Raw: map[string]interface{}{
+				"url":             server.URL + "/second",
+				"request_headers": map[string]interface{}{"X-Test": "second"},
+			},
+			ExpectConnections: 1,
+		},
+		{
+			Raw: map[string]interface{}{
+				"url":             server.URL + "/third",
+				"dial_timeout_ms": 5000,
+			},
+			ExpectConnections: 2,
+		},
+		{
+			Raw:               map[string]interface{}{"url": server.URL + "/fourth"},
+			ExpectConnections: 2,
+		},
+	}
+
+	for i, read := range reads {
+		d := schema.TestResourceDataRaw(t, dataSource().Schema, read.Raw)
+
+		if diags := dataSourceRead(context.Background(), d, p.Meta()); diags.HasError() {
+			t.Fatalf("unexpected error in read %d: %v", i, diags)
+		}
+
+		if n := atomic.LoadInt32(&connections); n != read.ExpectConnections {
+			t.Fatalf("expected %d connections after read %d, got %d", read.ExpectConnections, i, n)
+		}
+	}
+
+	config := p.Meta().(*providerConfig)
+	if n := len(config.transports); n != 2 {
+		t.Fatalf("expected 2 shared transports, got %d", n)
+	}
+	for _, tr := range config.transports {
+		if tr.MaxIdleConnsPerHost != 4 {
+			t.Fatalf("expected MaxIdleConnsPerHost 4, got %d", tr.MaxIdleConnsPerHost)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedTransport waits for the limiter before each round trip, so
+// retries, redirects and wait_for polling are limited too. The limiter is
+// shared by every request the provider sends.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		// A RoundTripper must close the request body, even on errors.
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitedTransport(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: &rateLimitedTransport{
+			base:    http.DefaultTransport,
+			limiter: rate.NewLimiter(10, 1),
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		resp.Body.Close()
+	}
+
+	for i := 1; i < len(times); i++ {
+		// Allow for timer and scheduling jitter.
+		if gap := times[i].Sub(times[i-1]); gap < 90*time.Millisecond {
+			t.Fatalf("request %d sent %s after the previous one; want at least 100ms", i, gap)
+		}
+	}
+}
+
+func TestRateLimitedTransport_canceled(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	// The only token is taken, so the request has to wait for the next one.
+	limiter := rate.NewLimiter(0.1, 1)
+	limiter.Allow()
+
+	client := &http.Client{
+		Transport: &rateLimitedTransport{
+			base:    http.DefaultTransport,
+			limiter: limiter,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("expected error, got none")
+	}
+
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("expected no requests, got %d", n)
+	}
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func requestResource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: requestResourceCreate,
+		ReadContext:   requestResourceRead,
+		UpdateContext: requestResourceUpdate,
+		DeleteContext: requestResourceDelete,
+
+		Schema: map[string]*schema.Schema{
+			"url": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The URL used for every lifecycle request unless overridden.",
+			},
+
+			"create_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The URL requested on create. Defaults to url.",
+			},
+
+			"update_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The URL requested on update. Defaults to url.",
+			},
+
+			"delete_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateURL(urlSchemes),
+				Description:  "The URL requested on destroy. Defaults to url.",
+			},
+
+			"create_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      http.MethodPost,
+				ValidateFunc: validateStringInSlice(requestMethods),
+				Description:  "The request method used on create.",
+			},
+
+			"update_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      http.MethodPut,
+				ValidateFunc: validateStringInSlice(requestMethods),
+				Description:  "The request method used on update.",
+			},
+
+			"delete_method": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      http.MethodDelete,
+				ValidateFunc: validateStringInSlice(requestMethods),
+				Description:  "The request method used on destroy.",
+			},
+
+			"request_headers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "A map of request headers sent with every lifecycle request.",
+			},
+
+			"request_body": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The request body sent on create and update.",
+			},
+
+			"response_body": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The body of the last create or update response.",
+			},
+
+			"response_headers": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "The headers of the last create or update response.",
+			},
+
+			"status_code": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The status code of the last create or update response.",
+			},
+		},
+	}
+}
+
+func requestResourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	method := d.Get("create_method").(string)
+	url := lifecycleURL(d, "create_url")
+
+	if err := sendLifecycleRequest(ctx, d, meta, method, url, true); err != nil {
+		return diag.Errorf("Error creating HTTP request resource: %s", err)
+	}
+
+	d.SetId(resource.UniqueId())
+
+	return nil
+}
+
+// requestResourceRead keeps the state as recorded by the last create or update,
+// as there is no generic way to read back a remote object.
+func requestResourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return nil
+}
+
+func requestResourceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// Changes that only affect destroy do not require a request.
+	if !d.HasChanges("url", "update_url", "update_method", "request_headers", "request_body") {
+		return nil
+	}
+
+	method := d.Get("update_method").(string)
+	url := lifecycleURL(d, "update_url")
+
+	if err := sendLifecycleRequest(ctx, d, meta, method, url, true); err != nil {
+		// Keep the previous state, as the remote object was not updated.
+		d.Partial(true)
+		return diag.Errorf("Error updating HTTP request resource: %s", err)
+	}
+
+	return nil
+}
+
+func requestResourceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	method := d.Get("delete_method").(string)
+	url := lifecycleURL(d, "delete_url")
+
+	if err := sendLifecycleRequest(ctx, d, meta, method, url, false); err != nil {
+		d.Partial(true)
+		return diag.Errorf("Error deleting HTTP request resource: %s", err)
+	}
+
+	d.SetId("")
+
+	return nil
+}
+
+// lifecycleURL returns the URL override for a lifecycle stage, falling back to
+// the url argument.
+func lifecycleURL(d *schema.ResourceData, key string) string {
+	if url := d.Get(key).(string); url != "" {
+		return url
+	}
+
+	return d.Get("url").(string)
+}
+
+// sendLifecycleRequest sends a request for one stage of the resource's
+// lifecycle. When record is true the response is stored in state. A 404
+// response is accepted on destroy, as the object is already gone.
+func sendLifecycleRequest(ctx context.Context, d *schema.ResourceData, meta interface{}, method, url string, record bool) error {
+	config := meta.(*providerConfig)
+
+	tr, err := config.defaultTransport()
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		Transport: config.transport(tr),
+		Timeout:   time.Duration(config.timeoutMs) * time.Millisecond,
+	}
+
+	var body io.Reader
+	if record {
+		body = strings.NewReader(d.Get("request_body").(string))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("Error creating request: %s", err)
+	}
+
+	req.Header.Set("User-Agent", defaultUserAgent())
+
+	for name, value := range config.defaultHeaders {
+		req.Header.Set(name, value)
+	}
+
+	for name, value := range d.Get("request_headers").(map[string]interface{}) {
+		req.Header.Set(name, value.(string))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Error making request: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if !isStatusCodeExpected(resp.StatusCode, nil) && (record || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("HTTP request error. Response code: %d", resp.StatusCode)
+	}
+
+	if !record {
+		return nil
+	}
+
+	var responseBody bytes.Buffer
+	if _, err := io.Copy(&responseBody, resp.Body); err != nil {
+		return fmt.Errorf("Error reading response body: %s", err)
+	}
+
+	d.Set("response_body", responseBody.String())
+	d.Set("status_code", resp.StatusCode)
+	if err := d.Set("response_headers", flattenResponseHeaders(resp.Header)); err != nil {
+		return fmt.Errorf("Error setting HTTP response headers: %s", err)
+	}
+
+	return nil
+}
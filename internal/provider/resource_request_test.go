@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// testObjectServer is a mock API that stores objects by path. Objects are
+// created with POST, replaced with PUT and removed with DELETE.
+type testObjectServer struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func setUpMockObjectServer() *testObjectServer {
+	s := &testObjectServer{
+		objects: make(map[string]string),
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		body, _ := ioutil.ReadAll(r.Body)
+		_, exists := s.objects[r.URL.Path]
+
+		w.Header().Set("Content-Type", "text/plain")
+		switch {
+		case r.Method == http.MethodPost && !exists:
+			s.objects[r.URL.Path] = string(body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("created," + string(body)))
+		case r.Method == http.MethodPut && exists:
+			s.objects[r.URL.Path] = string(body)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("updated," + string(body)))
+		case r.Method == http.MethodDelete && exists:
+			delete(s.objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case exists:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return s
+}
+
+func (s *testObjectServer) object(path string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.objects[path]
+	return v, ok
+}
+
+const testResourceConfig_request = `
+resource "http_request" "http_test" {
+  url = "%s/objects/1"
+
+  request_body = "%s"
+}
+
+output "response_body" {
+  value = http_request.http_test.response_body
+}
+
+output "status_code" {
+  value = http_request.http_test.status_code
+}
+`
+
+func TestResource_request(t *testing.T) {
+	testObjectServer := setUpMockObjectServer()
+
+	defer testObjectServer.server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		Providers: testProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			if _, ok := testObjectServer.object("/objects/1"); ok {
+				return fmt.Errorf("object /objects/1 still exists")
+			}
+
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(testResourceConfig_request, testObjectServer.server.URL, "first"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["response_body"].Value != "created,first" {
+						return fmt.Errorf(
+							`'response_body' output is %s; want 'created,first'`,
+							outputs["response_body"].Value,
+						)
+					}
+
+					if outputs["status_code"].Value != "201" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '201'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					if v, _ := testObjectServer.object("/objects/1"); v != "first" {
+						return fmt.Errorf("object /objects/1 is %q; want 'first'", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				Config: fmt.Sprintf(testResourceConfig_request, testObjectServer.server.URL, "second"),
+				Check: func(s *terraform.State) error {
+					outputs := s.RootModule().Outputs
+
+					if outputs["response_body"].Value != "updated,second" {
+						return fmt.Errorf(
+							`'response_body' output is %s; want 'updated,second'`,
+							outputs["response_body"].Value,
+						)
+					}
+
+					if outputs["status_code"].Value != "200" {
+						return fmt.Errorf(
+							`'status_code' output is %s; want '200'`,
+							outputs["status_code"].Value,
+						)
+					}
+
+					if v, _ := testObjectServer.object("/objects/1"); v != "second" {
+						return fmt.Errorf("object /objects/1 is %q; want 'second'", v)
+					}
+
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestResource_requestUpdateError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created," + string(body)))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	p := New()
+	if diags := p.Configure(ctx, terraform.NewResourceConfigRaw(map[string]interface{}{})); diags.HasError() {
+		t.Fatalf("unexpected error configuring provider: %v", diags)
+	}
+
+	apply := func(state *terraform.InstanceState, body string) (*terraform.InstanceState, error) {
+		r := requestResource()
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"url":          server.URL + "/objects/1",
+			"request_body": body,
+		})
+
+		diff, err := r.Diff(ctx, state, config, p.Meta())
+		if err != nil {
+			return nil, err
+		}
+
+		newState, diags := r.Apply(ctx, state, diff, p.Meta())
+		if diags.HasError() {
+			return newState, fmt.Errorf("%v", diags)
+		}
+
+		return newState, nil
+	}
+
+	state, err := apply(nil, "first")
+	if err != nil {
+		t.Fatalf("unexpected error creating resource: %s", err)
+	}
+
+	state, err = apply(state, "second")
+	if err == nil {
+		t.Fatal("expected an error updating resource")
+	}
+
+	if state.ID == "" {
+		t.Fatal("expected resource to remain in state")
+	}
+
+	want := map[string]string{
+		"request_body":  "first",
+		"response_body": "created,first",
+		"status_code":   "201",
+	}
+	for k, v := range want {
+		if got := state.Attributes[k]; got != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestResource_requestInvalidURL(t *testing.T) {
+	s := requestResource().Schema
+
+	for _, k := range []string{"url", "create_url", "update_url", "delete_url"} {
+		if _, errs := s[k].ValidateFunc("/objects/1", k); len(errs) == 0 {
+			t.Errorf("expected %s to reject a relative URL", k)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// retryConfig controls how failed requests are retried.
+type retryConfig struct {
+	// attempts is the number of retries made after the initial request.
+	attempts int
+	minDelay time.Duration
+	maxDelay time.Duration
+	// statusCodes are retried in addition to 429 and 5xx responses.
+	statusCodes []int
+}
+
+func expandRetryConfig(v []interface{}) retryConfig {
+	if len(v) == 0 || v[0] == nil {
+		return retryConfig{}
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return retryConfig{
+		attempts: m["attempts"].(int),
+		minDelay: time.Duration(m["min_delay_ms"].(int)) * time.Millisecond,
+		maxDelay: time.Duration(m["max_delay_ms"].(int)) * time.Millisecond,
+	}
+}
+
+// doRequestWithRetry sends the request, retrying on connection errors and 5xx
+// responses according to the retry configuration. It returns the last
+// response or error along with the total number of attempts made.
+func doRequestWithRetry(ctx context.Context, client *http.Client, req *http.Request, retry retryConfig) (*http.Response, int, error) {
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempt - 1, err
+			}
+			req.Body = body
+		}
+
+		resp, err := client.Do(req)
+		if attempt > retry.attempts || !shouldRetry(ctx, resp, err, retry.statusCodes) {
+			return resp, attempt, err
+		}
+
+		delay := retry.backoff(attempt)
+
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp, time.Now()); ok && retryAfter > delay {
+				delay = retryAfter
+				if delay > retry.maxDelay {
+					delay = retry.maxDelay
+				}
+			}
+
+			// Drain the body so the connection can be reused.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// doRequestWithFailover sends the request with doRequestWithRetry, then to
+// each of urls in turn while the previous URL failed with a connection error
+// or a 5xx response. prepare is called on the copy of the request made for
+// each URL before it is sent. It returns the response or error of the last
+// URL tried, its index counting the request's own URL as 0, and the total
+// number of attempts made.
+func doRequestWithFailover(ctx context.Context, client *http.Client, req *http.Request, urls []*url.URL, retry retryConfig, prepare func(*http.Request) error) (*http.Response, int, int, error) {
+	var total int
+	for i := 0; ; i++ {
+		resp, attempts, err := doRequestWithRetry(ctx, client, req, retry)
+		total += attempts
+		if i == len(urls) || ctx.Err() != nil || (err == nil && resp.StatusCode < 500) {
+			return resp, i, total, err
+		}
+
+		if resp != nil {
+			// Drain the body so the connection can be reused.
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		req, err = withURL(req, urls[i])
+		if err != nil {
+			return nil, i + 1, total, err
+		}
+		if err := prepare(req); err != nil {
+			return nil, i + 1, total, err
+		}
+	}
+}
+
+// withURL returns a copy of req sent to u with a fresh copy of the body. The
+// Host header follows the URL unless it was overridden.
+func withURL(req *http.Request, u *url.URL) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	r.URL = u
+	if req.Host == req.URL.Host {
+		r.Host = u.Host
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// shouldRetry reports whether a request is worth retrying. Connection errors,
+// 429 and 5xx responses and those with one of statusCodes are retried while
+// other 4xx responses are considered terminal. A request stopped by an open
+// circuit breaker or to a host or address that is not allowed would only fail
+// again.
+func shouldRetry(ctx context.Context, resp *http.Response, err error, statusCodes []int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		var circuitErr *circuitOpenError
+		var hostErr *hostNotAllowedError
+		var privateIPErr *privateIPError
+		return !errors.As(err, &circuitErr) && !errors.As(err, &hostErr) && !errors.As(err, &privateIPErr)
+	}
+
+	for _, statusCode := range statusCodes {
+		if resp.StatusCode == statusCode {
+			return true
+		}
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter returns the delay requested by the Retry-After header of a
+// 429 or 503 response, given either in seconds or as an HTTP date.
+func parseRetryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := t.Sub(now); delay > 0 {
+		return delay, true
+	}
+
+	return 0, true
+}
+
+// backoff returns the delay before the next attempt using exponential backoff
+// with jitter, bounded by the configured minimum and maximum delays.
+func (r retryConfig) backoff(attempt int) time.Duration {
+	delay := r.minDelay
+	for i := 1; i < attempt && delay < r.maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > r.maxDelay {
+		delay = r.maxDelay
+	}
+
+	if jitter := delay - r.minDelay; jitter > 0 {
+		delay = r.minDelay + time.Duration(rand.Int63n(int64(jitter)+1))
+	}
+
+	return delay
+}
+
+// attemptsSuffix describes how many attempts were made for inclusion in error
+// messages. Nothing is added when the request was only attempted once.
+func attemptsSuffix(attempts int) string {
+	if attempts <= 1 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%d attempts made)", attempts)
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryConfigBackoff(t *testing.T) {
+	retry := retryConfig{
+		attempts: 5,
+		minDelay: 10 * time.Millisecond,
+		maxDelay: 50 * time.Millisecond,
+	}
+
+	for attempt := 1; attempt <= retry.attempts; attempt++ {
+		delay := retry.backoff(attempt)
+		if delay < retry.minDelay || delay > retry.maxDelay {
+			t.Fatalf("attempt %d: delay %s is outside [%s, %s]", attempt, delay, retry.minDelay, retry.maxDelay)
+		}
+	}
+}
+
+func TestRetryConfigBackoff_noDelay(t *testing.T) {
+	retry := retryConfig{attempts: 2}
+
+	if delay := retry.backoff(2); delay != 0 {
+		t.Fatalf("expected no delay, got %s", delay)
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusNotFound:            false,
+		http.StatusConflict:            true,
+		http.StatusTooEarly:            false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusServiceUnavailable:  true,
+	}
+
+	for statusCode, expected := range cases {
+		t.Run(strconv.Itoa(statusCode), func(t *testing.T) {
+			resp := &http.Response{StatusCode: statusCode}
+			if actual := shouldRetry(context.Background(), resp, nil, []int{http.StatusConflict}); actual != expected {
+				t.Fatalf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		StatusCode int
+		RetryAfter string
+		Expected   time.Duration
+		ExpectOK   bool
+	}{
+		"seconds":         {StatusCode: 429, RetryAfter: "120", Expected: 2 * time.Minute, ExpectOK: true},
+		"http date":       {StatusCode: 503, RetryAfter: "Wed, 01 Jan 2020 00:00:30 GMT", Expected: 30 * time.Second, ExpectOK: true},
+		"past http date":  {StatusCode: 503, RetryAfter: "Tue, 31 Dec 2019 23:59:00 GMT", Expected: 0, ExpectOK: true},
+		"missing":         {StatusCode: 429},
+		"negative":        {StatusCode: 429, RetryAfter: "-1"},
+		"invalid":         {StatusCode: 429, RetryAfter: "soon"},
+		"ignored status":  {StatusCode: 500, RetryAfter: "120"},
+		"ignored success": {StatusCode: 200, RetryAfter: "120"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.StatusCode, Header: http.Header{}}
+			if tc.RetryAfter != "" {
+				resp.Header.Set("Retry-After", tc.RetryAfter)
+			}
+
+			actual, ok := parseRetryAfter(resp, now)
+			if ok != tc.ExpectOK {
+				t.Fatalf("expected ok %t, got %t", tc.ExpectOK, ok)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestDoRequestWithRetry_retryAfter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, attempts, err := doRequestWithRetry(context.Background(), server.Client(), req, retryConfig{attempts: 1, maxDelay: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Fatalf("expected status 200 after 2 attempts, got %d after %d", resp.StatusCode, attempts)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected the retry to wait at least 1s, waited %s", elapsed)
+	}
+}
+
+func TestDoRequestWithRetry_retryAfterMaxDelay(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, attempts, err := doRequestWithRetry(context.Background(), server.Client(), req, retryConfig{attempts: 1, maxDelay: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Fatalf("expected status 200 after 2 attempts, got %d after %d", resp.StatusCode, attempts)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("expected the retry to wait at most max_delay_ms, waited %s", elapsed)
+	}
+}
+
+func TestDoRequestWithFailover(t *testing.T) {
+	statusServer := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	}
+
+	down := statusServer(http.StatusOK)
+	down.Close()
+	unavailable := statusServer(http.StatusServiceUnavailable)
+	defer unavailable.Close()
+	notFound := statusServer(http.StatusNotFound)
+	defer notFound.Close()
+	ok := statusServer(http.StatusOK)
+	defer ok.Close()
+
+	cases := map[string]struct {
+		URLs           []string
+		ExpectedIndex  int
+		ExpectedStatus int
+		ExpectErr      bool
+	}{
+		"first": {
+			URLs:           []string{ok.URL, unavailable.URL},
+			ExpectedIndex:  0,
+			ExpectedStatus: http.StatusOK,
+		},
+		"connection error": {
+			URLs:           []string{down.URL, ok.URL},
+			ExpectedIndex:  1,
+			ExpectedStatus: http.StatusOK,
+		},
+		"5xx": {
+			URLs:           []string{unavailable.URL, down.URL, ok.URL},
+			ExpectedIndex:  2,
+			ExpectedStatus: http.StatusOK,
+		},
+		// A 4xx response is not failed over from.
+		"4xx": {
+			URLs:           []string{notFound.URL, ok.URL},
+			ExpectedIndex:  0,
+			ExpectedStatus: http.StatusNotFound,
+		},
+		"all 5xx": {
+			URLs:           []string{unavailable.URL, unavailable.URL},
+			ExpectedIndex:  1,
+			ExpectedStatus: http.StatusServiceUnavailable,
+		},
+		"all down": {
+			URLs:          []string{down.URL, down.URL},
+			ExpectedIndex: 1,
+			ExpectErr:     true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tc.URLs[0], nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var urls []*url.URL
+			for _, rawURL := range tc.URLs[1:] {
+				u, err := url.Parse(rawURL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				urls = append(urls, u)
+			}
+
+			var prepared []string
+			prepare := func(r *http.Request) error {
+				prepared = append(prepared, r.Host)
+				return nil
+			}
+
+			resp, index, attempts, err := doRequestWithFailover(context.Background(), http.DefaultClient, req, urls, retryConfig{}, prepare)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatalf("expected error, got status %d", resp.StatusCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				resp.Body.Close()
+
+				if resp.StatusCode != tc.ExpectedStatus {
+					t.Fatalf("expected status %d, got %d", tc.ExpectedStatus, resp.StatusCode)
+				}
+			}
+
+			if index != tc.ExpectedIndex || attempts != tc.ExpectedIndex+1 {
+				t.Fatalf("expected index %d after %d attempts, got %d after %d", tc.ExpectedIndex, tc.ExpectedIndex+1, index, attempts)
+			}
+
+			for i, host := range prepared {
+				if host != urls[i].Host {
+					t.Fatalf("expected request %d to be prepared for host %s, got %s", i+1, urls[i].Host, host)
+				}
+			}
+		})
+	}
+}
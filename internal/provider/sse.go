@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// sseConfig holds the settings of the sse block.
+type sseConfig struct {
+	// terminateOnEvent is the event type that ends the stream, if set.
+	terminateOnEvent string
+	timeout          time.Duration
+}
+
+func expandSSEConfig(v []interface{}) *sseConfig {
+	if len(v) == 0 || v[0] == nil {
+		return nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	return &sseConfig{
+		terminateOnEvent: m["terminate_on_event"].(string),
+		timeout:          time.Duration(m["timeout_ms"].(int)) * time.Millisecond,
+	}
+}
+
+// sseEvent is an event of a text/event-stream response.
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readSSEEvents reads the events of an event stream from body until the
+// terminating event, the end of the stream or the timeout, returning the
+// events along with the raw stream read. timedOut is set when the timeout
+// ended the stream. When limit is positive, an error is returned if more than
+// limit bytes are read.
+func readSSEEvents(body io.ReadCloser, c *sseConfig, limit int64) (events []sseEvent, raw []byte, timedOut bool, err error) {
+	// Closing the body unblocks a read waiting for the next event.
+	var expired int32
+	timer := time.AfterFunc(c.timeout, func() {
+		atomic.StoreInt32(&expired, 1)
+		body.Close()
+	})
+	defer timer.Stop()
+
+	var rawBuf bytes.Buffer
+	var r io.Reader = body
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+	scanner := bufio.NewScanner(io.TeeReader(r, &rawBuf))
+	scanner.Buffer(nil, bufio.MaxScanTokenSize*16)
+	scanner.Split(scanSSELines)
+
+	// The fields of the event being read. An event is dispatched at a blank
+	// line if it has data or an event type.
+	var current sseEvent
+	var data []string
+	var lastID string
+	dispatch := false
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if dispatch {
+				current.id = lastID
+				current.data = strings.Join(data, "\n")
+				if current.event == "" {
+					current.event = "message"
+				}
+				events = append(events, current)
+				if c.terminateOnEvent != "" && current.event == c.terminateOnEvent {
+					return events, rawBuf.Bytes(), false, nil
+				}
+			}
+			current, data, dispatch = sseEvent{}, nil, false
+			continue
+		}
+
+		// Lines starting with a colon are comments, often sent to keep the
+		// connection open.
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			current.event = value
+			dispatch = true
+		case "data":
+			data = append(data, value)
+			dispatch = true
+		case "id":
+			// The last event ID carries over to later events.
+			if !strings.ContainsRune(value, 0) {
+				lastID = value
+			}
+		}
+	}
+
+	if atomic.LoadInt32(&expired) == 1 {
+		return events, rawBuf.Bytes(), true, nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, false, err
+	}
+
+	if limit > 0 && int64(rawBuf.Len()) > limit {
+		return nil, nil, false, fmt.Errorf("Response body exceeds the max_response_body_bytes limit of %d bytes", limit)
+	}
+
+	// An event not followed by a blank line before the stream ends is
+	// incomplete and discarded.
+	return events, rawBuf.Bytes(), false, nil
+}
+
+// scanSSELines is a bufio.SplitFunc for the lines of an event stream, which
+// end in CRLF, LF or CR.
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the data may be followed by an LF.
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// flattenSSEEvents converts events to the sse_events attribute.
+func flattenSSEEvents(events []sseEvent) []interface{} {
+	result := make([]interface{}, 0, len(events))
+	for _, e := range events {
+		result = append(result, map[string]interface{}{
+			"id":    e.id,
+			"event": e.event,
+			"data":  e.data,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSSEEvents(t *testing.T) {
+	cases := map[string]struct {
+		Stream           string
+		TerminateOnEvent string
+		Limit            int64
+		ExpectEvents     []sseEvent
+		ExpectErr        string
+	}{
+		"events": {
+			Stream: "data: one\n\nevent: update\ndata: two\n\n",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "one"},
+				{event: "update", data: "two"},
+			},
+		},
+		"multi-line data": {
+			Stream: "data: first\ndata: second\n\n",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "first\nsecond"},
+			},
+		},
+		"comments and unknown fields": {
+			Stream: ": keep-alive\nretry: 1000\nfoo: bar\ndata:no space\n\n",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "no space"},
+			},
+		},
+		"line endings": {
+			Stream: "data: crlf\r\n\r\ndata: cr\r\rdata: lf\n\n",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "crlf"},
+				{event: "message", data: "cr"},
+				{event: "message", data: "lf"},
+			},
+		},
+		"ids carry over": {
+			Stream: "id: 1\ndata: one\n\ndata: two\n\nid: 3\ndata: three\n\n",
+			ExpectEvents: []sseEvent{
+				{id: "1", event: "message", data: "one"},
+				{id: "1", event: "message", data: "two"},
+				{id: "3", event: "message", data: "three"},
+			},
+		},
+		"event without data": {
+			Stream: "event: done\n\n\n",
+			ExpectEvents: []sseEvent{
+				{event: "done"},
+			},
+		},
+		"incomplete event": {
+			Stream: "data: one\n\ndata: partial",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "one"},
+			},
+		},
+		"terminator": {
+			Stream:           "data: one\n\nevent: done\ndata: bye\n\ndata: after\n\n",
+			TerminateOnEvent: "done",
+			ExpectEvents: []sseEvent{
+				{event: "message", data: "one"},
+				{event: "done", data: "bye"},
+			},
+		},
+		"limit": {
+			Stream:    "data: one\n\ndata: two\n\n",
+			Limit:     12,
+			ExpectErr: "Response body exceeds the max_response_body_bytes limit of 12 bytes",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &sseConfig{terminateOnEvent: tc.TerminateOnEvent, timeout: time.Minute}
+			events, raw, timedOut, err := readSSEEvents(ioutil.NopCloser(strings.NewReader(tc.Stream)), c, tc.Limit)
+			if tc.ExpectErr != "" {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				if err.Error() != tc.ExpectErr {
+					t.Fatalf("expected error %q, got %q", tc.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if timedOut {
+				t.Fatal("expected the stream not to time out")
+			}
+			if !reflect.DeepEqual(events, tc.ExpectEvents) {
+				t.Fatalf("expected events %v, got %v", tc.ExpectEvents, events)
+			}
+			if !strings.HasPrefix(tc.Stream, string(raw)) {
+				t.Fatalf("expected the raw stream to be a prefix of %q, got %q", tc.Stream, raw)
+			}
+		})
+	}
+}
+
+func TestReadSSEEventsTimeout(t *testing.T) {
+	// The writer is never closed, so the stream only ends with the timeout.
+	r, w := io.Pipe()
+	defer w.Close()
+	go w.Write([]byte("data: one\n\n"))
+
+	c := &sseConfig{terminateOnEvent: "done", timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	events, raw, timedOut, err := readSSEEvents(r, c, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !timedOut {
+		t.Fatal("expected the stream to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected the stream to time out promptly, took %s", elapsed)
+	}
+
+	expected := []sseEvent{{event: "message", data: "one"}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	if string(raw) != "data: one\n\n" {
+		t.Fatalf("expected raw stream %q, got %q", "data: one\n\n", raw)
+	}
+}
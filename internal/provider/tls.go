@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// tlsVersions maps the values accepted by tls_min_version and
+// tls_max_version to their protocol versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// tlsVersionNames are the keys of tlsVersions, for validation.
+var tlsVersionNames = []string{"1.0", "1.1", "1.2", "1.3"}
+
+// tlsCipherSuites maps the names accepted by tls_cipher_suites to their IDs.
+// Only the suites Go considers secure are offered, and TLS 1.3 suites are
+// left out as they cannot be configured.
+var tlsCipherSuites = func() map[string]uint16 {
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		for _, version := range suite.SupportedVersions {
+			if version < tls.VersionTLS13 {
+				suites[suite.Name] = suite.ID
+				break
+			}
+		}
+	}
+	return suites
+}()
+
+// tlsCipherSuiteNames are the keys of tlsCipherSuites, for validation.
+var tlsCipherSuiteNames = func() []string {
+	names := make([]string, 0, len(tlsCipherSuites))
+	for name := range tlsCipherSuites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// newTLSConfig builds the TLS client configuration used for the request
+// from the data source's TLS related arguments, falling back to the provider
+// defaults.
+func newTLSConfig(d *schema.ResourceData, config *providerConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: d.Get("insecure_skip_verify").(bool) || d.Get("skip_tls_verify").(bool),
+	}
+
+	clientCert := d.Get("client_cert_pem").(string)
+	clientKey := d.Get("client_key_pem").(string)
+
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if v := d.Get("tls_min_version").(string); v != "" {
+		tlsConfig.MinVersion = tlsVersions[v]
+	}
+
+	if v := d.Get("tls_max_version").(string); v != "" {
+		tlsConfig.MaxVersion = tlsVersions[v]
+	}
+
+	for _, name := range d.Get("tls_cipher_suites").([]interface{}) {
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, tlsCipherSuites[name.(string)])
+	}
+
+	if tlsConfig.MinVersion != 0 && tlsConfig.MaxVersion != 0 && tlsConfig.MinVersion > tlsConfig.MaxVersion {
+		return nil, fmt.Errorf("tls_min_version must be less than or equal to tls_max_version")
+	}
+
+	caCert := d.Get("ca_cert_pem").(string)
+	if caCert == "" {
+		caCert = config.caCertPEM
+	}
+
+	if caCert != "" {
+		rootCAs, err := parseCertPool([]byte(caCert))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing ca_cert_pem: %s", err)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if v := d.Get("pin_sha256").(string); v != "" {
+		pin, err := parsePinSHA256(v)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing pin_sha256: %s", err)
+		}
+		// The pin takes the place of verifying the chain and host name, so
+		// that self-signed certificates can be pinned too.
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = verifyPinSHA256(pin)
+	}
+
+	return tlsConfig, nil
+}
+
+// defaultTLSConfig builds a TLS client configuration from the provider-level
+// defaults alone.
+func (c *providerConfig) defaultTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if c.caCertPEM != "" {
+		rootCAs, err := parseCertPool([]byte(c.caCertPEM))
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing ca_cert_pem: %s", err)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+// peerCertificate returns the certificate the server identified itself with,
+// or nil if the connection did not use TLS.
+func peerCertificate(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	return state.PeerCertificates[0]
+}
+
+// parseCertPool builds a certificate pool from one or more PEM encoded
+// certificates. Unlike x509.CertPool.AppendCertsFromPEM, any block that fails
+// to parse is reported rather than silently skipped.
+func parseCertPool(data []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+
+	for i := 1; ; i++ {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			if i == 1 {
+				return nil, fmt.Errorf("no PEM encoded certificates found")
+			}
+			if len(bytes.TrimSpace(data)) > 0 {
+				return nil, fmt.Errorf("unexpected data after certificate %d", i-1)
+			}
+			return pool, nil
+		}
+
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("block %d: unexpected PEM block type %q", i, block.Type)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %s", i, err)
+		}
+		pool.AddCert(cert)
+	}
+}
+
+// parsePinSHA256 decodes a SHA-256 fingerprint given either in hex, optionally
+// with colons between the bytes as printed by openssl, or in base64.
+func parsePinSHA256(s string) ([]byte, error) {
+	if v := strings.ReplaceAll(s, ":", ""); len(v) == hex.EncodedLen(sha256.Size) {
+		if pin, err := hex.DecodeString(v); err == nil {
+			return pin, nil
+		}
+	}
+
+	pin, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(pin) != sha256.Size {
+		return nil, fmt.Errorf("expected a SHA-256 fingerprint as 64 hex digits or 44 base64 characters, got %q", s)
+	}
+
+	return pin, nil
+}
+
+// verifyPinSHA256 returns a function for tls.Config.VerifyPeerCertificate
+// that accepts the server's certificate when the SHA-256 fingerprint of
+// either its subject public key info or the whole certificate equals pin.
+func verifyPinSHA256(pin []byte) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("the server presented no certificate to check against pin_sha256")
+		}
+
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("parsing the server certificate: %s", err)
+		}
+
+		spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		fingerprint := sha256.Sum256(cert.Raw)
+		if bytes.Equal(spki[:], pin) || bytes.Equal(fingerprint[:], pin) {
+			return nil
+		}
+
+		return fmt.Errorf("the server certificate does not match pin_sha256, its public key fingerprint is %s and its certificate fingerprint is %s",
+			base64.StdEncoding.EncodeToString(spki[:]), hex.EncodeToString(fingerprint[:]))
+	}
+}
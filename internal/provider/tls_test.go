@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestParseCertPool(t *testing.T) {
+	ca := newTestCertificate(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}, nil)
+
+	cases := map[string]struct {
+		PEM       string
+		ExpectErr bool
+	}{
+		"single":          {PEM: ca.certPEM},
+		"bundle":          {PEM: ca.certPEM + "\n" + ca.certPEM},
+		"empty":           {PEM: "", ExpectErr: true},
+		"not pem":         {PEM: "not a certificate", ExpectErr: true},
+		"trailing data":   {PEM: ca.certPEM + "garbage", ExpectErr: true},
+		"private key":     {PEM: ca.keyPEM, ExpectErr: true},
+		"invalid content": {PEM: "-----BEGIN CERTIFICATE-----\nZm9v\n-----END CERTIFICATE-----\n", ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseCertPool([]byte(tc.PEM))
+			if tc.ExpectErr && err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if !tc.ExpectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestPeerCertificate(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "leaf"}}
+	issuer := &x509.Certificate{Subject: pkix.Name{CommonName: "issuer"}}
+
+	cases := map[string]struct {
+		State    *tls.ConnectionState
+		Expected *x509.Certificate
+	}{
+		"not tls":         {State: nil, Expected: nil},
+		"no certificates": {State: &tls.ConnectionState{}, Expected: nil},
+		"chain":           {State: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert, issuer}}, Expected: cert},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := peerCertificate(tc.State); actual != tc.Expected {
+				t.Fatalf("expected %v, got %v", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestParsePinSHA256(t *testing.T) {
+	sum := sha256.Sum256([]byte("test"))
+	hexPin := hex.EncodeToString(sum[:])
+
+	var colons []string
+	for i := 0; i < len(hexPin); i += 2 {
+		colons = append(colons, strings.ToUpper(hexPin[i:i+2]))
+	}
+
+	cases := map[string]struct {
+		Value     string
+		ExpectErr bool
+	}{
+		"hex":          {Value: hexPin},
+		"hex colons":   {Value: strings.Join(colons, ":")},
+		"base64":       {Value: base64.StdEncoding.EncodeToString(sum[:])},
+		"empty":        {Value: "", ExpectErr: true},
+		"short hex":    {Value: hexPin[2:], ExpectErr: true},
+		"short base64": {Value: base64.StdEncoding.EncodeToString(sum[1:]), ExpectErr: true},
+		"sha1":         {Value: "da39a3ee5e6b4b0d3255bfef95601890afd80709", ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pin, err := parsePinSHA256(tc.Value)
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(pin) != string(sum[:]) {
+				t.Fatalf("expected %x, got %x", sum, pin)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfig_pinSHA256(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cert := server.Certificate()
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	fingerprint := sha256.Sum256(cert.Raw)
+	wrong := sha256.Sum256([]byte("wrong"))
+
+	cases := map[string]struct {
+		Pin       string
+		ExpectErr bool
+	}{
+		"public key":  {Pin: base64.StdEncoding.EncodeToString(spki[:])},
+		"certificate": {Pin: hex.EncodeToString(fingerprint[:])},
+		"wrong":       {Pin: base64.StdEncoding.EncodeToString(wrong[:]), ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+				"url":        server.URL,
+				"pin_sha256": tc.Pin,
+			})
+
+			tlsConfig, err := newTLSConfig(d, &providerConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if tc.ExpectErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("expected error, got none")
+				}
+				if !strings.Contains(err.Error(), "does not match pin_sha256") {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			resp.Body.Close()
+		})
+	}
+}
+
+func TestNewTLSConfig_cipherSuites(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	cases := map[string]struct {
+		Suite     string
+		ExpectErr bool
+	}{
+		"offered by server":     {Suite: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+		"not offered by server": {Suite: "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", ExpectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSource().Schema, map[string]interface{}{
+				"url":                  server.URL,
+				"insecure_skip_verify": true,
+				"tls_cipher_suites":    []interface{}{tc.Suite},
+			})
+
+			tlsConfig, err := newTLSConfig(d, &providerConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+			resp, err := client.Get(server.URL)
+			if tc.ExpectErr {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("expected error, got none")
+				}
+				if !strings.Contains(err.Error(), "handshake failure") {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if resp.TLS.CipherSuite != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+				t.Fatalf("expected cipher suite %s, got %s", tc.Suite, tls.CipherSuiteName(resp.TLS.CipherSuite))
+			}
+			resp.Body.Close()
+		})
+	}
+}
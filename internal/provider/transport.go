@@ -0,0 +1,334 @@
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// transportArguments are the data source arguments newTLSConfig and
+// newTransport read. Data sources that agree on all of them share a transport.
+var transportArguments = []string{
+	"insecure_skip_verify",
+	"skip_tls_verify",
+	"client_cert_pem",
+	"client_key_pem",
+	"tls_min_version",
+	"tls_max_version",
+	"tls_cipher_suites",
+	"ca_cert_pem",
+	"pin_sha256",
+	"dial_timeout_ms",
+	"local_address",
+	"block_private_ips",
+	"allowed_private_cidrs",
+	"tls_handshake_timeout_ms",
+	"expect_continue_timeout_ms",
+	"disable_keep_alives",
+	"disable_http2",
+	"proxy_url",
+	"no_proxy",
+	"resolve",
+	"unix_socket",
+}
+
+// transportKey identifies the transport for the data source among those
+// shared by the provider.
+func transportKey(d *schema.ResourceData) string {
+	values := make([]interface{}, len(transportArguments))
+	for i, name := range transportArguments {
+		values[i] = d.Get(name)
+	}
+
+	// The values are strings, numbers, booleans and lists and maps of
+	// strings, which are always encoded. Map keys are sorted.
+	key, _ := json.Marshal(values)
+
+	return string(key)
+}
+
+// newTransport builds the HTTP transport used for the request from the data
+// source's connection related arguments.
+func newTransport(d *schema.ResourceData, tlsConfig *tls.Config) (*http.Transport, error) {
+	// The dial timeout also bounds DNS resolution.
+	dialer := &net.Dialer{
+		Timeout: time.Duration(d.Get("dial_timeout_ms").(int)) * time.Millisecond,
+	}
+
+	// Only addresses of the same family as the local address are connected
+	// to.
+	if v := d.Get("local_address").(string); v != "" {
+		ip := net.ParseIP(v)
+		if ip == nil {
+			return nil, fmt.Errorf("Error parsing local_address: %q is not a valid IP address", v)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	if d.Get("block_private_ips").(bool) {
+		var allowed []*net.IPNet
+		for _, v := range d.Get("allowed_private_cidrs").([]interface{}) {
+			_, network, err := net.ParseCIDR(v.(string))
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing allowed_private_cidrs: %s", err)
+			}
+			allowed = append(allowed, network)
+		}
+		dialer.Control = blockPrivateIPs(allowed)
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig:     tlsConfig,
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: time.Duration(d.Get("tls_handshake_timeout_ms").(int)) * time.Millisecond,
+		// Setting TLSClientConfig otherwise disables the automatic HTTP/2
+		// support of http.DefaultTransport.
+		ForceAttemptHTTP2: true,
+	}
+
+	if v := d.Get("expect_continue_timeout_ms").(int); v > 0 {
+		tr.ExpectContinueTimeout = time.Duration(v) * time.Millisecond
+	}
+
+	if d.Get("disable_keep_alives").(bool) {
+		tr.DisableKeepAlives = true
+	}
+
+	if d.Get("disable_http2").(bool) {
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if proxyURL := d.Get("proxy_url").(string); proxyURL != "" {
+		u, err := parseProxyURL(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing proxy_url: %s", err)
+		}
+		tr.Proxy = http.ProxyURL(u)
+
+		if v := d.Get("no_proxy").([]interface{}); len(v) > 0 {
+			noProxy, err := parseNoProxy(v)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing no_proxy: %s", err)
+			}
+			tr.Proxy = func(req *http.Request) (*url.URL, error) {
+				if noProxy.matches(req.URL) {
+					return nil, nil
+				}
+				return u, nil
+			}
+		}
+	}
+
+	if v := d.Get("resolve").(map[string]interface{}); len(v) > 0 {
+		resolve, err := parseResolveOverrides(v)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing resolve: %s", err)
+		}
+		// Only the dialled address changes, so the Host header, TLS server
+		// name and certificate verification still use the host in the URL.
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if override, ok := resolve[addr]; ok {
+				addr = override
+			}
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	if socketPath := d.Get("unix_socket").(string); socketPath != "" {
+		tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		// The socket is always dialled directly.
+		tr.Proxy = nil
+	}
+
+	return tr, nil
+}
+
+// timeoutMessage describes the timeout that ended a request. A dial or TLS
+// handshake timeout is only blamed when it is shorter than the request
+// timeout, as otherwise the request timeout expired first. Without a request
+// timeout the duration is left out, as the timeout was not one configured.
+func timeoutMessage(err error, requestTimeout, dialTimeout, tlsHandshakeTimeout int) string {
+	shorter := func(timeout int) bool {
+		return timeout > 0 && (requestTimeout == 0 || timeout < requestTimeout)
+	}
+
+	var opErr *net.OpError
+	dialErr := errors.As(err, &opErr) && opErr.Op == "dial"
+
+	switch {
+	case shorter(dialTimeout) && dialErr:
+		return fmt.Sprintf("Connection timed out after %dms", dialTimeout)
+	case shorter(tlsHandshakeTimeout) && strings.Contains(err.Error(), "TLS handshake timeout"):
+		return fmt.Sprintf("TLS handshake timed out after %dms", tlsHandshakeTimeout)
+	case requestTimeout == 0 && dialErr:
+		return "Connection timed out"
+	case requestTimeout == 0:
+		return "HTTP request timed out"
+	default:
+		return fmt.Sprintf("HTTP request timed out after %dms", requestTimeout)
+	}
+}
+
+// parseResolveOverrides converts the resolve argument, which maps host:port
+// pairs to IP addresses, into a map of dial address substitutions.
+func parseResolveOverrides(v map[string]interface{}) (map[string]string, error) {
+	resolve := make(map[string]string, len(v))
+	for hostPort, value := range v {
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return nil, err
+		}
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("%q must be in the form host:port", hostPort)
+		}
+
+		ip := net.ParseIP(value.(string))
+		if ip == nil {
+			return nil, fmt.Errorf("%q is not a valid IP address for %q", value, hostPort)
+		}
+
+		resolve[net.JoinHostPort(strings.ToLower(host), port)] = net.JoinHostPort(ip.String(), port)
+	}
+
+	return resolve, nil
+}
+
+// parseProxyURL parses a proxy URL, allowing only the schemes supported by
+// http.Transport.
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q, must be one of http, https or socks5", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in %q", rawURL)
+	}
+
+	return u, nil
+}
+
+// noProxyRule is one entry of the no_proxy argument. A rule matches by
+// network, by IP address or by domain name, and optionally only on one port.
+type noProxyRule struct {
+	network *net.IPNet
+	ip      net.IP
+	// domain matches the host itself, unless subdomainsOnly is set, and any
+	// subdomain.
+	domain         string
+	subdomainsOnly bool
+	port           string
+}
+
+// noProxyRules are the hosts requests are sent to directly rather than through
+// proxy_url.
+type noProxyRules struct {
+	all   bool
+	rules []noProxyRule
+}
+
+// parseNoProxy parses the no_proxy argument. Entries follow the NO_PROXY
+// conventions: "*" matches every host, CIDR blocks match IP addresses in the
+// network, "example.com" matches the domain and its subdomains and
+// ".example.com" or "*.example.com" match only its subdomains. IP addresses
+// and domains may be followed by a port.
+func parseNoProxy(v []interface{}) (*noProxyRules, error) {
+	n := &noProxyRules{}
+
+	for _, e := range v {
+		entry := strings.ToLower(strings.TrimSpace(e.(string)))
+
+		switch {
+		case entry == "":
+			return nil, fmt.Errorf("empty entry")
+		case entry == "*":
+			n.all = true
+		case strings.Contains(entry, "/"):
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return nil, err
+			}
+			n.rules = append(n.rules, noProxyRule{network: network})
+		default:
+			var rule noProxyRule
+			host := entry
+			if h, port, err := net.SplitHostPort(entry); err == nil {
+				host, rule.port = h, port
+			}
+			if ip := net.ParseIP(host); ip != nil {
+				rule.ip = ip
+			} else {
+				if strings.HasPrefix(host, "*.") {
+					host = host[1:]
+				}
+				if strings.HasPrefix(host, ".") {
+					rule.subdomainsOnly = true
+					host = host[1:]
+				}
+				if host == "" {
+					return nil, fmt.Errorf("invalid entry %q", entry)
+				}
+				rule.domain = host
+			}
+			n.rules = append(n.rules, rule)
+		}
+	}
+
+	return n, nil
+}
+
+// matches reports whether a request to u bypasses the proxy.
+func (n *noProxyRules) matches(u *url.URL) bool {
+	if n.all {
+		return true
+	}
+
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+
+	for _, rule := range n.rules {
+		if rule.port != "" && rule.port != port {
+			continue
+		}
+
+		switch {
+		case rule.network != nil:
+			if ip != nil && rule.network.Contains(ip) {
+				return true
+			}
+		case rule.ip != nil:
+			if ip != nil && rule.ip.Equal(ip) {
+				return true
+			}
+		default:
+			if strings.HasSuffix(host, "."+rule.domain) || (!rule.subdomainsOnly && host == rule.domain) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,331 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// validateIntAtLeast returns a SchemaValidateFunc which tests if the provided
+// value is of type int and is at least min (inclusive).
+func validateIntAtLeast(min int) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(int)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be int", k))
+			return warnings, errors
+		}
+
+		if v < min {
+			errors = append(errors, fmt.Errorf("expected %s to be at least (%d), got %d", k, min, v))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateFloatGreaterThan returns a SchemaValidateFunc which tests if the
+// provided value is of type float64 and is greater than min (exclusive).
+func validateFloatGreaterThan(min float64) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(float64)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be float", k))
+			return warnings, errors
+		}
+
+		if v <= min {
+			errors = append(errors, fmt.Errorf("expected %s to be greater than (%g), got %g", k, min, v))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateStringInSlice returns a SchemaValidateFunc which tests if the provided
+// value is of type string and matches the value of an element in the valid
+// slice.
+func validateStringInSlice(valid []string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		for _, str := range valid {
+			if v == str {
+				return warnings, errors
+			}
+		}
+
+		errors = append(errors, fmt.Errorf("expected %s to be one of [%s], got %q", k, strings.Join(valid, ", "), v))
+		return warnings, errors
+	}
+}
+
+// validateRegexp returns a SchemaValidateFunc which tests if the provided value
+// is of type string and is a valid regular expression.
+func validateRegexp() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, err := regexp.Compile(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid regular expression: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateJSON returns a SchemaValidateFunc which tests if the provided value
+// is of type string and is a well-formed JSON document.
+func validateJSON() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal([]byte(v), &doc); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be valid JSON: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateBase64 returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is valid standard base64 with padding.
+func validateBase64() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be valid base64: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateHTTPTime returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is a time in RFC 1123 or RFC 3339 format.
+func validateHTTPTime() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, err := parseHTTPTime(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid time: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateMediaType returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is a media type such as text/plain;
+// charset=utf-8.
+func validateMediaType() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, _, err := mime.ParseMediaType(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid media type: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateBodyTemplate returns a SchemaValidateFunc which tests if the
+// provided value is of type string and is a valid Go text/template.
+func validateBodyTemplate() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, err := parseBodyTemplate(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid template: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validatePinSHA256 returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is a SHA-256 fingerprint in hex or base64.
+func validatePinSHA256() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, err := parsePinSHA256(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid fingerprint: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateURL returns a SchemaValidateFunc which tests if the provided value is
+// of type string and is an absolute URL with one of the given schemes.
+func validateURL(schemes []string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		u, err := url.ParseRequestURI(v)
+		if err != nil || u.Scheme == "" {
+			errors = append(errors, fmt.Errorf("expected %s to be an absolute URL such as https://example.com/path, got %q", k, v))
+			return warnings, errors
+		}
+
+		if u.Host == "" {
+			errors = append(errors, fmt.Errorf("expected %s to have a host, got %q", k, v))
+			return warnings, errors
+		}
+
+		for _, scheme := range schemes {
+			if strings.EqualFold(u.Scheme, scheme) {
+				return warnings, errors
+			}
+		}
+
+		errors = append(errors, fmt.Errorf("expected %s to have one of the schemes [%s], got %q", k, strings.Join(schemes, ", "), u.Scheme))
+		return warnings, errors
+	}
+}
+
+// validateOrigin returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is an origin such as https://example.com: an
+// absolute URL with one of the given schemes and nothing after the host.
+func validateOrigin(schemes []string) schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		warnings, errors = validateURL(schemes)(i, k)
+		if len(errors) > 0 {
+			return warnings, errors
+		}
+
+		v := i.(string)
+		u, _ := url.Parse(v)
+		if u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(v, "?") || strings.HasSuffix(v, "#") {
+			errors = append(errors, fmt.Errorf("expected %s to be an origin of the form scheme://host[:port], got %q", k, v))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateHostGlob returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is a host name glob such as *.example.com.
+func validateHostGlob() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if v == "" || strings.ContainsAny(v, "/:") {
+			errors = append(errors, fmt.Errorf("expected %s to be a host name glob such as *.example.com, without scheme or port, got %q", k, v))
+			return warnings, errors
+		}
+
+		if _, err := path.Match(v, ""); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a valid glob: %s", k, err))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateIPAddress returns a SchemaValidateFunc which tests if the provided
+// value is of type string and is an IPv4 or IPv6 address.
+func validateIPAddress() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if net.ParseIP(v) == nil {
+			errors = append(errors, fmt.Errorf("expected %s to be an IP address, got %q", k, v))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validatePathSegment returns a SchemaValidateFunc which tests if the
+// provided value is of type string and is a path segment other than "", "."
+// and "..", which would change the path rather than add to it.
+func validatePathSegment() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if v == "" || v == "." || v == ".." {
+			errors = append(errors, fmt.Errorf("expected %s to be a path segment other than \"\", \".\" and \"..\", got %q", k, v))
+		}
+
+		return warnings, errors
+	}
+}
+
+// validateCIDR returns a SchemaValidateFunc which tests if the provided value
+// is of type string and is a CIDR block such as 10.0.0.0/8.
+func validateCIDR() schema.SchemaValidateFunc {
+	return func(i interface{}, k string) (warnings []string, errors []error) {
+		v, ok := i.(string)
+		if !ok {
+			errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+			return warnings, errors
+		}
+
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			errors = append(errors, fmt.Errorf("expected %s to be a CIDR block such as 10.0.0.0/8, got %q", k, v))
+		}
+
+		return warnings, errors
+	}
+}
@@ -0,0 +1,609 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestValidateIntAtLeast(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		Min      int
+		ErrCount int
+	}{
+		"equal": {
+			Value: 0,
+			Min:   0,
+		},
+		"greater": {
+			Value: 10,
+			Min:   0,
+		},
+		"less": {
+			Value:    -1,
+			Min:      0,
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    "10",
+			Min:      0,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateIntAtLeast(tc.Min)(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateFloatGreaterThan(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		Min      float64
+		ErrCount int
+	}{
+		"greater": {
+			Value: 0.5,
+			Min:   0,
+		},
+		"equal": {
+			Value:    0.0,
+			Min:      0,
+			ErrCount: 1,
+		},
+		"less": {
+			Value:    -1.0,
+			Min:      0,
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    1,
+			Min:      0,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateFloatGreaterThan(tc.Min)(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateStringInSlice(t *testing.T) {
+	valid := []string{"GET", "POST"}
+
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"valid": {
+			Value: "POST",
+		},
+		"invalid": {
+			Value:    "PUT",
+			ErrCount: 1,
+		},
+		"different case": {
+			Value:    "get",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    1,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateStringInSlice(valid)(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateRegexp(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"valid": {
+			Value: `^"status":\s*"ok"$`,
+		},
+		"empty": {
+			Value: "",
+		},
+		"invalid": {
+			Value:    "(unclosed",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateRegexp()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"object": {
+			Value: `{"name": "test", "tags": [1, 2]}`,
+		},
+		"scalar": {
+			Value: `"test"`,
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"invalid": {
+			Value:    `{"name": }`,
+			ErrCount: 1,
+		},
+		"trailing data": {
+			Value:    `{} {}`,
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateJSON()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateBase64(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"binary": {
+			Value: "AP8QgA==",
+		},
+		"empty": {
+			Value: "",
+		},
+		"missing padding": {
+			Value:    "AP8QgA",
+			ErrCount: 1,
+		},
+		"invalid character": {
+			Value:    "AP8Q*A==",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateBase64()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPTime(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"rfc 1123": {
+			Value: "Wed, 01 Jan 2020 00:00:00 GMT",
+		},
+		"rfc 3339": {
+			Value: "2020-01-01T00:00:00+01:00",
+		},
+		"date only": {
+			Value:    "2020-01-01",
+			ErrCount: 1,
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateHTTPTime()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateMediaType(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"type": {
+			Value: "application/xml",
+		},
+		"parameters": {
+			Value: "text/plain; charset=utf-8",
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"invalid parameter": {
+			Value:    "text/plain; charset",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateMediaType()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateBodyTemplate(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"variable": {
+			Value: `{"name": "{{.name}}"}`,
+		},
+		"no actions": {
+			Value: "static",
+		},
+		"unclosed action": {
+			Value:    "{{.name",
+			ErrCount: 1,
+		},
+		"undefined function": {
+			Value:    "{{upper .name}}",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateBodyTemplate()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidatePinSHA256(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"hex": {
+			Value: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		},
+		"base64": {
+			Value: "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=",
+		},
+		"too short": {
+			Value:    "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validatePinSHA256()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"http": {
+			Value: "http://example.com/path?query=1",
+		},
+		"https upper case": {
+			Value: "HTTPS://example.com",
+		},
+		"relative": {
+			Value:    "/path",
+			ErrCount: 1,
+		},
+		"missing scheme": {
+			Value:    "example.com/path",
+			ErrCount: 1,
+		},
+		"missing host": {
+			Value:    "http:///path",
+			ErrCount: 1,
+		},
+		"unsupported scheme": {
+			Value:    "ftp://example.com/file",
+			ErrCount: 1,
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateURL([]string{"http", "https"})(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateOrigin(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"host": {
+			Value: "https://example.com",
+		},
+		"port": {
+			Value: "http://localhost:8080",
+		},
+		"trailing slash": {
+			Value:    "https://example.com/",
+			ErrCount: 1,
+		},
+		"path": {
+			Value:    "https://example.com/page",
+			ErrCount: 1,
+		},
+		"query": {
+			Value:    "https://example.com?a=1",
+			ErrCount: 1,
+		},
+		"user info": {
+			Value:    "https://user@example.com",
+			ErrCount: 1,
+		},
+		"missing scheme": {
+			Value:    "example.com",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateOrigin([]string{"http", "https"})(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateHostGlob(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"host": {
+			Value: "api.example.com",
+		},
+		"subdomains": {
+			Value: "*.example.com",
+		},
+		"character class": {
+			Value: "10.0.0.[1-9]",
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"scheme": {
+			Value:    "https://example.com",
+			ErrCount: 1,
+		},
+		"port": {
+			Value:    "example.com:443",
+			ErrCount: 1,
+		},
+		"malformed": {
+			Value:    "[a-",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateHostGlob()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateIPAddress(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"ipv4": {
+			Value: "192.168.0.10",
+		},
+		"ipv6": {
+			Value: "fd00::10",
+		},
+		"host name": {
+			Value:    "localhost",
+			ErrCount: 1,
+		},
+		"cidr": {
+			Value:    "10.0.0.0/8",
+			ErrCount: 1,
+		},
+		"with port": {
+			Value:    "127.0.0.1:8080",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateIPAddress()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidatePathSegment(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"segment": {
+			Value: "a b",
+		},
+		"slash": {
+			Value: "c/d",
+		},
+		"dots in name": {
+			Value: "v1.2",
+		},
+		"empty": {
+			Value:    "",
+			ErrCount: 1,
+		},
+		"dot": {
+			Value:    ".",
+			ErrCount: 1,
+		},
+		"dot dot": {
+			Value:    "..",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validatePathSegment()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
+
+func TestValidateCIDR(t *testing.T) {
+	cases := map[string]struct {
+		Value    interface{}
+		ErrCount int
+	}{
+		"ipv4": {
+			Value: "10.0.0.0/8",
+		},
+		"ipv6": {
+			Value: "fd00::/8",
+		},
+		"address without prefix": {
+			Value:    "10.0.0.1",
+			ErrCount: 1,
+		},
+		"invalid prefix": {
+			Value:    "10.0.0.0/33",
+			ErrCount: 1,
+		},
+		"wrong type": {
+			Value:    10,
+			ErrCount: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, errs := validateCIDR()(tc.Value, "test")
+			if len(errs) != tc.ErrCount {
+				t.Fatalf("expected %d errors, got %d: %v", tc.ErrCount, len(errs), errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// waitConfig holds the settings of the wait_for block.
+type waitConfig struct {
+	// statusCode is the status code to wait for, or 0 for any.
+	statusCode int
+	// bodyRegex must match the response body, if set.
+	bodyRegex *regexp.Regexp
+	timeout   time.Duration
+	interval  time.Duration
+}
+
+func expandWaitConfig(v []interface{}) (*waitConfig, error) {
+	if len(v) == 0 || v[0] == nil {
+		return nil, nil
+	}
+
+	m := v[0].(map[string]interface{})
+
+	wait := &waitConfig{
+		statusCode: m["status_code"].(int),
+		timeout:    time.Duration(m["timeout_ms"].(int)) * time.Millisecond,
+		interval:   time.Duration(m["interval_ms"].(int)) * time.Millisecond,
+	}
+
+	if bodyRegex := m["body_regex"].(string); bodyRegex != "" {
+		re, err := regexp.Compile(bodyRegex)
+		if err != nil {
+			return nil, err
+		}
+		wait.bodyRegex = re
+	}
+
+	if wait.statusCode == 0 && wait.bodyRegex == nil {
+		return nil, fmt.Errorf("wait_for requires status_code or body_regex")
+	}
+
+	return wait, nil
+}
+
+// matches reports whether a response satisfies the wait condition.
+func (w *waitConfig) matches(statusCode int, body []byte) bool {
+	if w.statusCode != 0 && statusCode != w.statusCode {
+		return false
+	}
+
+	return w.bodyRegex == nil || w.bodyRegex.Match(body)
+}
+
+// doRequestUntil sends the request, with retries, every interval until the
+// response matches the wait condition or the timeout elapses. The body of
+// each response is read, limited to limit bytes when positive, so the
+// returned response has a buffered body. It also returns the total number of
+// attempts made.
+func doRequestUntil(ctx context.Context, client *http.Client, req *http.Request, retry retryConfig, wait *waitConfig, limit int64) (*http.Response, int, error) {
+	deadline := time.Now().Add(wait.timeout)
+	total := 0
+
+	var lastErr error
+	var lastResp *http.Response
+	var lastBody []byte
+
+	for {
+		if total > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, total, err
+			}
+			req.Body = body
+		}
+
+		resp, attempts, err := doRequestWithRetry(ctx, client, req, retry)
+		total += attempts
+
+		if err == nil {
+			body, readErr := readResponseBody(resp.Body, limit)
+			resp.Body.Close()
+			if readErr != nil {
+				return nil, total, readErr
+			}
+
+			resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+			if wait.matches(resp.StatusCode, body) {
+				return resp, total, nil
+			}
+
+			lastErr, lastResp, lastBody = nil, resp, body
+		} else {
+			lastErr, lastResp, lastBody = err, nil, nil
+		}
+
+		if time.Now().Add(wait.interval).After(deadline) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, total, ctx.Err()
+		case <-time.After(wait.interval):
+		}
+	}
+
+	if lastResp == nil {
+		return nil, total, fmt.Errorf("timed out after %s waiting for wait_for condition, last error: %s", wait.timeout, lastErr)
+	}
+
+	return nil, total, fmt.Errorf("timed out after %s waiting for wait_for condition, last status code: %d, last body: %q", wait.timeout, lastResp.StatusCode, lastBody)
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestWaitConfigMatches(t *testing.T) {
+	cases := map[string]struct {
+		Block      map[string]interface{}
+		StatusCode int
+		Body       string
+		Match      bool
+	}{
+		"status code": {
+			Block:      map[string]interface{}{"status_code": 200, "body_regex": ""},
+			StatusCode: 200,
+			Match:      true,
+		},
+		"other status code": {
+			Block:      map[string]interface{}{"status_code": 200, "body_regex": ""},
+			StatusCode: 503,
+		},
+		"body regex": {
+			Block:      map[string]interface{}{"status_code": 0, "body_regex": `"status":\s*"ok"`},
+			StatusCode: 503,
+			Body:       `{"status": "ok"}`,
+			Match:      true,
+		},
+		"other body": {
+			Block:      map[string]interface{}{"status_code": 0, "body_regex": `"status":\s*"ok"`},
+			StatusCode: 200,
+			Body:       `{"status": "starting"}`,
+		},
+		"both": {
+			Block:      map[string]interface{}{"status_code": 200, "body_regex": "ok"},
+			StatusCode: 200,
+			Body:       "ok",
+			Match:      true,
+		},
+		"both with other status code": {
+			Block:      map[string]interface{}{"status_code": 200, "body_regex": "ok"},
+			StatusCode: 503,
+			Body:       "ok",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.Block["timeout_ms"] = 1000
+			tc.Block["interval_ms"] = 100
+
+			wait, err := expandWaitConfig([]interface{}{tc.Block})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual := wait.matches(tc.StatusCode, []byte(tc.Body)); actual != tc.Match {
+				t.Fatalf("expected %t, got %t", tc.Match, actual)
+			}
+		})
+	}
+}
+
+func TestExpandWaitConfig_noCondition(t *testing.T) {
+	_, err := expandWaitConfig([]interface{}{map[string]interface{}{
+		"status_code": 0,
+		"body_regex":  "",
+		"timeout_ms":  1000,
+		"interval_ms": 100,
+	}})
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// isContentTypeXML reports whether the content type is application/xml,
+// text/xml or a structured syntax suffix of them, such as
+// application/soap+xml.
+func isContentTypeXML(contentType string) bool {
+	parsedType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return parsedType == "application/xml" || parsedType == "text/xml" || strings.HasSuffix(parsedType, "+xml")
+}
+
+// xmlElement is an element of a parsed XML document.
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlElement
+	text     strings.Builder
+}
+
+// xmlToJSON converts an XML document to JSON. The document becomes an object
+// with the root element's name as its only key. An element with neither
+// attributes nor child elements becomes its text content. Other elements
+// become objects where attributes are keys prefixed with "@", child elements
+// are keys named after them, with an array when a name is repeated, and any
+// text content is under "#text". Namespace prefixes are dropped.
+func xmlToJSON(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Character sets other than UTF-8 are passed through unchanged.
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	var root *xmlElement
+	var stack []*xmlElement
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &xmlElement{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				// Namespace declarations are not attributes of the element.
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				e.attrs = append(e.attrs, attr)
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			} else if root != nil {
+				return "", fmt.Errorf("multiple root elements")
+			} else {
+				root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return "", fmt.Errorf("no root element")
+	}
+
+	out, err := json.Marshal(map[string]interface{}{root.name: root.value()})
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// value returns the JSON representation of the element.
+func (e *xmlElement) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+
+	if len(e.attrs) == 0 && len(e.children) == 0 {
+		return text
+	}
+
+	obj := make(map[string]interface{})
+	for _, attr := range e.attrs {
+		obj["@"+attr.Name.Local] = attr.Value
+	}
+
+	for _, child := range e.children {
+		v := child.value()
+		switch existing := obj[child.name].(type) {
+		case nil:
+			obj[child.name] = v
+		case []interface{}:
+			obj[child.name] = append(existing, v)
+		default:
+			obj[child.name] = []interface{}{existing, v}
+		}
+	}
+
+	if text != "" {
+		obj["#text"] = text
+	}
+
+	return obj
+}
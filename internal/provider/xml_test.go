@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestXMLToJSON(t *testing.T) {
+	cases := map[string]struct {
+		Input     string
+		Expected  string
+		ExpectErr bool
+	}{
+		"text element": {
+			Input:    `<version>1.0.0</version>`,
+			Expected: `{"version":"1.0.0"}`,
+		},
+		"empty element": {
+			Input:    `<empty/>`,
+			Expected: `{"empty":""}`,
+		},
+		"attributes and text": {
+			Input:    `<price currency="EUR">10.50</price>`,
+			Expected: `{"price":{"#text":"10.50","@currency":"EUR"}}`,
+		},
+		"repeated children": {
+			Input:    `<list><item>a</item><item id="2">b</item><other/></list>`,
+			Expected: `{"list":{"item":["a",{"#text":"b","@id":"2"}],"other":""}}`,
+		},
+		"namespaces": {
+			Input:    `<?xml version="1.0"?><soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><m:Result xmlns:m="urn:test">ok</m:Result></soap:Body></soap:Envelope>`,
+			Expected: `{"Envelope":{"Body":{"Result":"ok"}}}`,
+		},
+		"invalid": {
+			Input:     `<a><b></a>`,
+			ExpectErr: true,
+		},
+		"empty input": {
+			Input:     ``,
+			ExpectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := xmlToJSON([]byte(tc.Input))
+			if tc.ExpectErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if actual != tc.Expected {
+				t.Fatalf("expected %s, got %s", tc.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsContentTypeXML(t *testing.T) {
+	cases := map[string]bool{
+		"application/xml":                  true,
+		"text/xml; charset=utf-8":          true,
+		"application/soap+xml":             true,
+		"application/json":                 false,
+		"text/plain":                       false,
+		"not a media type; charset=utf-8;": false,
+	}
+
+	for contentType, expected := range cases {
+		t.Run(contentType, func(t *testing.T) {
+			if actual := isContentTypeXML(contentType); actual != expected {
+				t.Fatalf("expected %t, got %t", expected, actual)
+			}
+		})
+	}
+}